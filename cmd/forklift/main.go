@@ -30,6 +30,6 @@ import (
 func main() {
 	if err := cmd.Execute(); err != nil {
 		fmt.Fprint(os.Stderr, err.Error())
-		os.Exit(1)
+		os.Exit(cmd.ExitCode(err))
 	}
 }
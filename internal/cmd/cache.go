@@ -0,0 +1,96 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/docker/go-units"
+	"github.com/homeport/forklift/pkg/cache"
+	"github.com/homeport/forklift/pkg/misc"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type byteSize int64
+
+var _ pflag.Value = new(byteSize)
+
+func (s *byteSize) String() string {
+	return humanReadableSize(int64(*s))
+}
+
+func (s *byteSize) Set(value string) error {
+	size, err := units.RAMInBytes(value)
+	*s = byteSize(size)
+	return err
+}
+
+func (s *byteSize) Type() string {
+	return "size"
+}
+
+var cacheCmdSettings = struct {
+	dir     string
+	maxSize byteSize
+	noCache bool
+}{
+	maxSize: 10 << 30,
+}
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the local blob cache",
+	Long: `Manage the local blob cache, which stores the manifests, configs, and layers
+of images loaded from registries, so that later commands do not download them
+again. The least recently used blobs are removed when the cache exceeds its
+maximum size (--cache-max-size).`,
+}
+
+// setupCache configures the blob cache used when loading images from
+// registries, the directory can also be set using FORKLIFT_CACHE_DIR
+func setupCache(cmd *cobra.Command) {
+	if cacheCmdSettings.noCache {
+		misc.Cache = nil
+		return
+	}
+
+	misc.Cache = blobCache(cmd)
+}
+
+func blobCache(cmd *cobra.Command) *cache.Cache {
+	var dir = cacheCmdSettings.dir
+	if !cmd.Flags().Changed("cache-dir") {
+		if env, ok := os.LookupEnv("FORKLIFT_CACHE_DIR"); ok && env != "" {
+			dir = env
+		}
+	}
+
+	return &cache.Cache{Dir: dir, MaxSize: int64(cacheCmdSettings.maxSize)}
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+
+	rootCmd.PersistentFlags().StringVar(&cacheCmdSettings.dir, "cache-dir", cache.DefaultDir(), "Directory of the blob cache (env FORKLIFT_CACHE_DIR)")
+	rootCmd.PersistentFlags().Var(&cacheCmdSettings.maxSize, "cache-max-size", "Maximum size of the blob cache, for example 500MiB or 20GiB (0 means no limit)")
+	rootCmd.PersistentFlags().BoolVar(&cacheCmdSettings.noCache, "no-cache", false, "Do not use the blob cache for images loaded from registries")
+}
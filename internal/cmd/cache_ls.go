@@ -0,0 +1,62 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var cacheLsCmd = &cobra.Command{
+	Use:          "ls",
+	Aliases:      []string{"list"},
+	Args:         cobra.NoArgs,
+	Short:        "List cached blobs",
+	Long:         `Lists the blobs in the cache, least recently used first`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		var cache = blobCache(cmd)
+		entries, err := cache.Entries()
+		if err != nil {
+			return err
+		}
+
+		return render(entries, func() error {
+			table := newTable()
+			setHeader(table, "Digest", "Size", "Last Used")
+
+			for _, entry := range entries {
+				table.Append([]string{
+					entry.Digest.String(),
+					humanReadableSize(entry.Size),
+					entry.LastUsed.Format("2006-01-02 15:04:05"),
+				})
+			}
+
+			table.Render()
+			pout("\n%d blobs, %s in %s\n", len(entries), humanReadableSize(totalSize(entries)), cache.Dir)
+			return nil
+		})
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheLsCmd)
+}
@@ -0,0 +1,85 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"time"
+
+	"github.com/homeport/forklift/pkg/cache"
+	"github.com/spf13/cobra"
+)
+
+var cachePruneCmdSettings struct {
+	olderThan time.Duration
+	maxSize   byteSize
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Args:  cobra.NoArgs,
+	Short: "Remove unused blobs from the cache",
+	Long: `Removes blobs that were not used for some time (--older-than) from the cache,
+as well as left-overs of interrupted downloads. With --max-size, least recently
+used blobs are removed until the cache is not larger than the given size.`,
+	Example: `  forklift cache prune --older-than 168h
+  forklift cache prune --max-size 2GiB`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		var blobs = blobCache(cmd)
+
+		removed, err := blobs.Prune(time.Now().Add(-cachePruneCmdSettings.olderThan))
+		if err != nil {
+			return err
+		}
+
+		if cmd.Flags().Changed("max-size") {
+			evicted, err := blobs.Evict(int64(cachePruneCmdSettings.maxSize))
+			if err != nil {
+				return err
+			}
+
+			removed = append(removed, evicted...)
+		}
+
+		return render(removed, func() error {
+			pout("removed %d blobs (%s)\n", len(removed), humanReadableSize(totalSize(removed)))
+			return nil
+		})
+	},
+}
+
+func totalSize(entries []cache.Entry) int64 {
+	var total int64
+	for _, entry := range entries {
+		total += entry.Size
+	}
+
+	return total
+}
+
+func init() {
+	cacheCmd.AddCommand(cachePruneCmd)
+
+	cachePruneCmd.Flags().SortFlags = false
+
+	cachePruneCmd.Flags().DurationVar(&cachePruneCmdSettings.olderThan, "older-than", 30*24*time.Hour, "Remove blobs that were not used for this long")
+	cachePruneCmd.Flags().Var(&cachePruneCmdSettings.maxSize, "max-size", "Remove least recently used blobs until the cache is not larger than this")
+}
@@ -21,10 +21,14 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 
 	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/homeport/forklift/pkg/misc"
 	"github.com/spf13/pflag"
 )
 
@@ -47,9 +51,34 @@ func (t *tag) Type() string {
 	return "image tag"
 }
 
-func pout(format string, a ...any) {
-	// TODO Make target configurable via root command-line flag
-	_, _ = fmt.Fprintf(os.Stdout, format, a...)
+// loadImage parses the given image reference and loads the image
+func loadImage(ctx context.Context, input string) (v1.Image, error) {
+	ref, err := name.ParseReference(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return misc.LoadImage(ctx, ref)
+}
+
+// targetTag returns the explicitly configured target, or a tag derived from
+// the image reference using the given suffix
+func targetTag(ref name.Reference, target tag, suffix string) (name.Tag, error) {
+	if target.RegistryStr() != "" {
+		return target.Tag, nil
+	}
+
+	return name.NewTag(ref.String() + "-" + suffix)
+}
+
+// writeJSON writes the value as indented JSON into the file
+func writeJSON(filename string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, append(data, '\n'), 0644)
 }
 
 func humanReadableSize(bytes int64) string {
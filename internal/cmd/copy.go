@@ -0,0 +1,140 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"compress/gzip"
+	"fmt"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/homeport/forklift/pkg/misc"
+	"github.com/homeport/forklift/pkg/repackage"
+	"github.com/spf13/cobra"
+)
+
+var copyCmdSettings struct {
+	platform         string
+	recompress       bool
+	compressionLevel int
+}
+
+var copyCmd = &cobra.Command{
+	Use:   "copy <source> <destination>",
+	Args:  cobra.ExactArgs(2),
+	Short: "Copy an image or image index",
+	Long: `Copies an image or a whole image index between registries, the Docker daemon,
+OCI layout directories, and Docker archives. Locations use these schemes:
+
+  docker://<reference>                 registry
+  docker-daemon:<reference>            Docker daemon
+  oci:<path>[:<tag>]                   OCI layout directory
+  docker-archive:<path>[:<reference>]  Docker archive (docker save)
+
+A source without scheme is looked up in the Docker daemon first and then in
+the registry, a destination without scheme is a registry. Registry writes skip
+blobs that already exist and mount blobs from other repositories of the same
+registry instead of uploading them.`,
+	Example: `  forklift copy alpine:3 docker://registry.example.com/base/alpine:3
+  forklift copy docker://alpine:3 oci:./alpine:3 --platform linux/arm64`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateCompressionLevel(copyCmdSettings.compressionLevel); err != nil {
+			return err
+		}
+
+		src, err := misc.ParseLocation(args[0])
+		if err != nil {
+			return err
+		}
+
+		dst, err := misc.ParseLocation(args[1])
+		if err != nil {
+			return err
+		}
+
+		artifact, err := misc.Load(cmd.Context(), src)
+		if err != nil {
+			return err
+		}
+
+		if copyCmdSettings.platform != "" {
+			platform, err := v1.ParsePlatform(copyCmdSettings.platform)
+			if err != nil {
+				return err
+			}
+
+			if artifact, err = artifact.Select(*platform); err != nil {
+				return err
+			}
+		}
+
+		if copyCmdSettings.recompress {
+			if artifact.Index != nil {
+				artifact.Index, err = repackage.RecompressIndex(artifact.Index, repackage.WithCompressionLevel(copyCmdSettings.compressionLevel))
+			} else {
+				artifact.Image, err = repackage.Recompress(artifact.Image, repackage.WithCompressionLevel(copyCmdSettings.compressionLevel))
+			}
+
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := misc.Write(cmd.Context(), dst, artifact); err != nil {
+			return fmt.Errorf("failed to copy %s to %s: %w", src, dst, err)
+		}
+
+		digest, err := artifact.Digest()
+		if err != nil {
+			return err
+		}
+
+		var result = struct {
+			Source string  `json:"source"`
+			Target string  `json:"target"`
+			Digest v1.Hash `json:"digest"`
+		}{src.String(), dst.String(), digest}
+
+		return render(result, func() error {
+			pout("copied %s to %s (%s)\n", src, dst, digest)
+			return nil
+		})
+	},
+}
+
+// validateCompressionLevel checks the gzip compression level of new layers
+func validateCompressionLevel(level int) error {
+	if level < gzip.HuffmanOnly || level > gzip.BestCompression {
+		return fmt.Errorf("invalid compression level %d, use a value from %d to %d", level, gzip.HuffmanOnly, gzip.BestCompression)
+	}
+
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(copyCmd)
+
+	copyCmd.Flags().SortFlags = false
+
+	copyCmd.Flags().StringVar(&copyCmdSettings.platform, "platform", "", "Copy only the image for the platform (os/arch[/variant]) of an image index")
+	copyCmd.Flags().BoolVar(&copyCmdSettings.recompress, "recompress", false, "Re-encode all layers using the gzip compression level")
+	copyCmd.Flags().IntVar(&copyCmdSettings.compressionLevel, "compression-level", gzip.DefaultCompression, "Gzip compression level used by --recompress (1 fastest to 9 best, -1 default)")
+}
@@ -0,0 +1,195 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/homeport/forklift/pkg/misc"
+	"github.com/homeport/forklift/pkg/tar"
+	"github.com/spf13/cobra"
+)
+
+var imageAppendCmdSettings struct {
+	add              []string
+	chown            string
+	chmod            string
+	tmpDir           string
+	compressionLevel int
+	target           tag
+}
+
+var imageAppendCmd = &cobra.Command{
+	Use:   "append <image-reference>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Append files or directories as a new layer",
+	Long: `Appends local files or directories to an image as one new layer, for example
+to add a CA bundle or a configuration file to a third-party image.`,
+	Example:      `  forklift image append alpine:3 --add ca.pem:/etc/ssl/certs/ca.pem --chown 0:0 --chmod 0644`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateCompressionLevel(imageAppendCmdSettings.compressionLevel); err != nil {
+			return err
+		}
+
+		ref, err := name.ParseReference(args[0])
+		if err != nil {
+			return err
+		}
+
+		target, err := targetTag(ref, imageAppendCmdSettings.target, "appended")
+		if err != nil {
+			return err
+		}
+
+		if len(imageAppendCmdSettings.add) == 0 {
+			return fmt.Errorf("no files to append, use --add <local-path>:<image-path>")
+		}
+
+		var opts = []tar.Option{tar.WithTempDir(imageAppendCmdSettings.tmpDir)}
+		var flags []string
+
+		if imageAppendCmdSettings.chown != "" {
+			uid, gid, err := parseOwner(imageAppendCmdSettings.chown)
+			if err != nil {
+				return err
+			}
+
+			opts = append(opts, tar.WithOwner(uid, gid))
+			flags = append(flags, "--chown="+imageAppendCmdSettings.chown)
+		}
+
+		if imageAppendCmdSettings.chmod != "" {
+			mode, err := strconv.ParseUint(imageAppendCmdSettings.chmod, 8, 32)
+			if err != nil {
+				return fmt.Errorf("invalid file mode %q: %w", imageAppendCmdSettings.chmod, err)
+			}
+
+			opts = append(opts, tar.WithMode(fs.FileMode(mode)))
+			flags = append(flags, "--chmod="+imageAppendCmdSettings.chmod)
+		}
+
+		var entries []tar.Entry
+		var createdBy []string
+		for _, add := range imageAppendCmdSettings.add {
+			source, dest, ok := strings.Cut(add, ":")
+			if !ok || source == "" || dest == "" {
+				return fmt.Errorf("%q does not match the expected format of <local-path>:<image-path>", add)
+			}
+
+			entries = append(entries, tar.Entry{Source: source, Target: dest})
+			createdBy = append(createdBy, strings.Join(slices.Concat([]string{"COPY"}, flags, []string{filepath.Base(source), dest}), " "))
+		}
+
+		image, err := misc.LoadImage(cmd.Context(), ref)
+		if err != nil {
+			return err
+		}
+
+		layers, err := image.Layers()
+		if err != nil {
+			return err
+		}
+
+		files, err := tar.Files(layers...)
+		if err != nil {
+			return err
+		}
+
+		opts = append(opts, tar.WithExisting(func(name string) bool {
+			_, ok := files[name]
+			return ok
+		}))
+
+		tmpball, err := tar.CreateFrom(entries, opts...)
+		if err != nil {
+			return err
+		}
+
+		defer func() { _ = misc.RemoveTemp(tmpball) }()
+
+		layer, err := tarball.LayerFromFile(tmpball.Name(), tarball.WithCompressionLevel(imageAppendCmdSettings.compressionLevel))
+		if err != nil {
+			return err
+		}
+
+		result, err := mutate.Append(image, mutate.Addendum{
+			Layer: layer,
+			History: v1.History{
+				Author:    "forklift",
+				Comment:   "appended files",
+				Created:   v1.Time{Time: time.Now().UTC()},
+				CreatedBy: strings.Join(createdBy, ", "),
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		return misc.SaveImage(cmd.Context(), target, result)
+	},
+}
+
+// parseOwner reads numeric user and group IDs in the format uid[:gid], where
+// the group ID defaults to the user ID
+func parseOwner(input string) (uid int, gid int, err error) {
+	user, group, ok := strings.Cut(input, ":")
+
+	uid, err = strconv.Atoi(user)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid user ID %q, only numeric IDs are supported: %w", user, err)
+	}
+
+	if !ok {
+		return uid, uid, nil
+	}
+
+	gid, err = strconv.Atoi(group)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid group ID %q, only numeric IDs are supported: %w", group, err)
+	}
+
+	return uid, gid, nil
+}
+
+func init() {
+	imageCmd.AddCommand(imageAppendCmd)
+
+	imageAppendCmd.Flags().SortFlags = false
+
+	imageAppendCmd.Flags().StringArrayVar(&imageAppendCmdSettings.add, "add", nil, "Local file or directory to add (<local-path>:<image-path>)")
+	imageAppendCmd.Flags().StringVar(&imageAppendCmdSettings.chown, "chown", "", "Set user and group ID of the added files (uid[:gid])")
+	imageAppendCmd.Flags().StringVar(&imageAppendCmdSettings.chmod, "chmod", "", "Set permissions of the added files in octal notation")
+	imageAppendCmd.Flags().StringVar(&imageAppendCmdSettings.tmpDir, "tmp-dir", "", "Directory for temporary files (default is the system temporary directory)")
+	imageAppendCmd.Flags().IntVar(&imageAppendCmdSettings.compressionLevel, "compression-level", gzip.DefaultCompression, "Gzip compression level of new layers (1 fastest to 9 best, -1 default)")
+	imageAppendCmd.Flags().VarP(&imageAppendCmdSettings.target, "target", "t", "target")
+}
@@ -0,0 +1,328 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/homeport/forklift/pkg/config"
+	"github.com/homeport/forklift/pkg/interactive"
+	"github.com/homeport/forklift/pkg/misc"
+	"github.com/spf13/cobra"
+)
+
+var imageConfigCmdSettings struct {
+	edit   bool
+	target tag
+}
+
+var imageConfigSetCmdSettings struct {
+	env        []string
+	labels     []string
+	expose     []string
+	entrypoint string
+	cmd        string
+	user       string
+	workdir    string
+	stopSignal string
+}
+
+var imageConfigUnsetCmdSettings struct {
+	env        []string
+	labels     []string
+	expose     []string
+	entrypoint bool
+	cmd        bool
+	user       bool
+	workdir    bool
+	stopSignal bool
+}
+
+var imageConfigCmd = &cobra.Command{
+	Use:   "config <image-reference>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Show or edit the image config",
+	Long: `Shows the image config (environment, entrypoint, labels, and so on), or edits
+it interactively using the --edit flag.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ref, image, cfg, err := loadImageConfig(cmd, args[0])
+		if err != nil {
+			return err
+		}
+
+		if !imageConfigCmdSettings.edit {
+			return render(cfg, func() error {
+				data, err := config.ToYAML(cfg)
+				if err != nil {
+					return err
+				}
+
+				pout("%s", data)
+				return nil
+			})
+		}
+
+		data, err := config.ToYAML(cfg)
+		if err != nil {
+			return err
+		}
+
+		text, err := interactive.Edit(string(data))
+		if err != nil {
+			return err
+		}
+
+		cfg, err = config.FromYAML([]byte(text))
+		if err != nil {
+			return err
+		}
+
+		return saveImageConfig(cmd.Context(), ref, image, cfg)
+	},
+}
+
+var imageConfigSetCmd = &cobra.Command{
+	Use:          "set <image-reference>",
+	Args:         cobra.ExactArgs(1),
+	Short:        "Set image config settings",
+	Long:         `Sets environment variables, labels, exposed ports, entrypoint, command, user, working directory, or stop signal of the image config.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ref, image, cfg, err := loadImageConfig(cmd, args[0])
+		if err != nil {
+			return err
+		}
+
+		var settings = imageConfigSetCmdSettings
+
+		for _, entry := range settings.env {
+			key, value, ok := strings.Cut(entry, "=")
+			if !ok {
+				return fmt.Errorf("environment variable %q does not match the expected format of KEY=VALUE", entry)
+			}
+
+			config.SetEnv(&cfg, key, value)
+		}
+
+		for _, entry := range settings.labels {
+			key, value, ok := strings.Cut(entry, "=")
+			if !ok {
+				return fmt.Errorf("label %q does not match the expected format of KEY=VALUE", entry)
+			}
+
+			if cfg.Labels == nil {
+				cfg.Labels = map[string]string{}
+			}
+
+			cfg.Labels[key] = value
+		}
+
+		for _, port := range settings.expose {
+			if cfg.ExposedPorts == nil {
+				cfg.ExposedPorts = map[string]struct{}{}
+			}
+
+			cfg.ExposedPorts[normalizePort(port)] = struct{}{}
+		}
+
+		var flags = cmd.Flags()
+
+		if flags.Changed("entrypoint") {
+			if cfg.Entrypoint, err = parseCommand(settings.entrypoint); err != nil {
+				return err
+			}
+		}
+
+		if flags.Changed("cmd") {
+			if cfg.Cmd, err = parseCommand(settings.cmd); err != nil {
+				return err
+			}
+		}
+
+		if flags.Changed("user") {
+			cfg.User = settings.user
+		}
+
+		if flags.Changed("workdir") {
+			cfg.WorkingDir = settings.workdir
+		}
+
+		if flags.Changed("stop-signal") {
+			cfg.StopSignal = settings.stopSignal
+		}
+
+		return saveImageConfig(cmd.Context(), ref, image, cfg)
+	},
+}
+
+var imageConfigUnsetCmd = &cobra.Command{
+	Use:          "unset <image-reference>",
+	Args:         cobra.ExactArgs(1),
+	Short:        "Unset image config settings",
+	Long:         `Removes environment variables, labels, exposed ports, entrypoint, command, user, working directory, or stop signal from the image config.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ref, image, cfg, err := loadImageConfig(cmd, args[0])
+		if err != nil {
+			return err
+		}
+
+		var settings = imageConfigUnsetCmdSettings
+
+		for _, key := range settings.env {
+			config.UnsetEnv(&cfg, key)
+		}
+
+		for _, key := range settings.labels {
+			delete(cfg.Labels, key)
+		}
+
+		for _, port := range settings.expose {
+			delete(cfg.ExposedPorts, normalizePort(port))
+		}
+
+		if settings.entrypoint {
+			cfg.Entrypoint = nil
+		}
+
+		if settings.cmd {
+			cfg.Cmd = nil
+		}
+
+		if settings.user {
+			cfg.User = ""
+		}
+
+		if settings.workdir {
+			cfg.WorkingDir = ""
+		}
+
+		if settings.stopSignal {
+			cfg.StopSignal = ""
+		}
+
+		return saveImageConfig(cmd.Context(), ref, image, cfg)
+	},
+}
+
+func loadImageConfig(cmd *cobra.Command, input string) (name.Reference, v1.Image, v1.Config, error) {
+	ref, err := name.ParseReference(input)
+	if err != nil {
+		return nil, nil, v1.Config{}, err
+	}
+
+	image, err := misc.LoadImage(cmd.Context(), ref)
+	if err != nil {
+		return nil, nil, v1.Config{}, err
+	}
+
+	configFile, err := image.ConfigFile()
+	if err != nil {
+		return nil, nil, v1.Config{}, err
+	}
+
+	return ref, image, *configFile.Config.DeepCopy(), nil
+}
+
+func saveImageConfig(ctx context.Context, ref name.Reference, image v1.Image, cfg v1.Config) error {
+	configFile, err := image.ConfigFile()
+	if err != nil {
+		return err
+	}
+
+	changes := config.Describe(configFile.Config, cfg)
+	if len(changes) == 0 {
+		pinfo("no changes to the image config\n")
+		return nil
+	}
+
+	target, err := targetTag(ref, imageConfigCmdSettings.target, "configured")
+	if err != nil {
+		return err
+	}
+
+	result, err := config.Image(image, cfg)
+	if err != nil {
+		return err
+	}
+
+	pinfo("updated image config: %s\n", strings.Join(changes, ", "))
+	return misc.SaveImage(ctx, target, result)
+}
+
+// parseCommand reads either a JSON array (exec form), or a whitespace
+// separated list of arguments
+func parseCommand(input string) ([]string, error) {
+	if strings.HasPrefix(strings.TrimSpace(input), "[") {
+		var result []string
+		if err := json.Unmarshal([]byte(input), &result); err != nil {
+			return nil, fmt.Errorf("failed to parse command %q: %w", input, err)
+		}
+
+		return result, nil
+	}
+
+	return strings.Fields(input), nil
+}
+
+// normalizePort adds the default tcp protocol if none is given
+func normalizePort(port string) string {
+	if !strings.Contains(port, "/") {
+		return port + "/tcp"
+	}
+
+	return port
+}
+
+func init() {
+	imageCmd.AddCommand(imageConfigCmd)
+	imageConfigCmd.AddCommand(imageConfigSetCmd)
+	imageConfigCmd.AddCommand(imageConfigUnsetCmd)
+
+	imageConfigCmd.Flags().BoolVarP(&imageConfigCmdSettings.edit, "edit", "e", false, "Edit the image config interactively")
+	imageConfigCmd.PersistentFlags().VarP(&imageConfigCmdSettings.target, "target", "t", "target")
+
+	imageConfigSetCmd.Flags().SortFlags = false
+	imageConfigSetCmd.Flags().StringArrayVar(&imageConfigSetCmdSettings.env, "env", nil, "Set environment variable (KEY=VALUE)")
+	imageConfigSetCmd.Flags().StringArrayVar(&imageConfigSetCmdSettings.labels, "label", nil, "Set label (KEY=VALUE)")
+	imageConfigSetCmd.Flags().StringArrayVar(&imageConfigSetCmdSettings.expose, "expose", nil, "Expose port (PORT[/PROTOCOL])")
+	imageConfigSetCmd.Flags().StringVar(&imageConfigSetCmdSettings.entrypoint, "entrypoint", "", "Set entrypoint (JSON array or whitespace separated)")
+	imageConfigSetCmd.Flags().StringVar(&imageConfigSetCmdSettings.cmd, "cmd", "", "Set command (JSON array or whitespace separated)")
+	imageConfigSetCmd.Flags().StringVar(&imageConfigSetCmdSettings.user, "user", "", "Set user")
+	imageConfigSetCmd.Flags().StringVar(&imageConfigSetCmdSettings.workdir, "workdir", "", "Set working directory")
+	imageConfigSetCmd.Flags().StringVar(&imageConfigSetCmdSettings.stopSignal, "stop-signal", "", "Set stop signal")
+
+	imageConfigUnsetCmd.Flags().SortFlags = false
+	imageConfigUnsetCmd.Flags().StringArrayVar(&imageConfigUnsetCmdSettings.env, "env", nil, "Remove environment variable (KEY)")
+	imageConfigUnsetCmd.Flags().StringArrayVar(&imageConfigUnsetCmdSettings.labels, "label", nil, "Remove label (KEY)")
+	imageConfigUnsetCmd.Flags().StringArrayVar(&imageConfigUnsetCmdSettings.expose, "expose", nil, "Remove exposed port (PORT[/PROTOCOL])")
+	imageConfigUnsetCmd.Flags().BoolVar(&imageConfigUnsetCmdSettings.entrypoint, "entrypoint", false, "Remove entrypoint")
+	imageConfigUnsetCmd.Flags().BoolVar(&imageConfigUnsetCmdSettings.cmd, "cmd", false, "Remove command")
+	imageConfigUnsetCmd.Flags().BoolVar(&imageConfigUnsetCmdSettings.user, "user", false, "Remove user")
+	imageConfigUnsetCmd.Flags().BoolVar(&imageConfigUnsetCmdSettings.workdir, "workdir", false, "Remove working directory")
+	imageConfigUnsetCmd.Flags().BoolVar(&imageConfigUnsetCmdSettings.stopSignal, "stop-signal", false, "Remove stop signal")
+}
@@ -0,0 +1,174 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/homeport/forklift/pkg/history"
+	"github.com/homeport/forklift/pkg/interactive"
+	"github.com/homeport/forklift/pkg/misc"
+	"github.com/spf13/cobra"
+)
+
+var imageHistoryCmdSettings struct {
+	edit   bool
+	redact []string
+	target tag
+}
+
+var imageHistoryCmd = &cobra.Command{
+	Use:   "history <image-reference>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Show or rewrite the image history",
+	Long: `Lists all history entries of the image, including the ones without a layer.
+
+The history can be rewritten interactively using --edit, or by redacting all
+matches of regular expressions using --redact, for example to remove build
+arguments that leaked into the CreatedBy strings. Entries that belong to a
+layer cannot be removed or re-ordered.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ref, err := name.ParseReference(args[0])
+		if err != nil {
+			return err
+		}
+
+		image, err := misc.LoadImage(cmd.Context(), ref)
+		if err != nil {
+			return err
+		}
+
+		configFile, err := image.ConfigFile()
+		if err != nil {
+			return err
+		}
+
+		if !imageHistoryCmdSettings.edit && len(imageHistoryCmdSettings.redact) == 0 {
+			return printHistory(configFile.History)
+		}
+
+		var entries = configFile.History
+
+		if len(imageHistoryCmdSettings.redact) > 0 {
+			var regexes = make([]*regexp.Regexp, 0, len(imageHistoryCmdSettings.redact))
+			for _, expr := range imageHistoryCmdSettings.redact {
+				regex, err := regexp.Compile(expr)
+				if err != nil {
+					return fmt.Errorf("invalid regular expression %q: %w", expr, err)
+				}
+
+				regexes = append(regexes, regex)
+			}
+
+			var changed int
+			entries, changed = history.Redact(entries, regexes...)
+
+			switch {
+			case changed > 0:
+				pinfo("redacted %d history entries\n", changed)
+				if image, err = history.Image(image, entries); err != nil {
+					return err
+				}
+
+			case !imageHistoryCmdSettings.edit:
+				pinfo("no history entries match, image is not written\n")
+				return nil
+			}
+		}
+
+		if imageHistoryCmdSettings.edit {
+			data, err := history.ToYAML(image)
+			if err != nil {
+				return err
+			}
+
+			text, err := interactive.Edit(string(data))
+			if err != nil {
+				return err
+			}
+
+			entries, err = history.FromYAML(image, []byte(text))
+			if err != nil {
+				return err
+			}
+
+			if image, err = history.Image(image, entries); err != nil {
+				return err
+			}
+		}
+
+		target, err := targetTag(ref, imageHistoryCmdSettings.target, "history")
+		if err != nil {
+			return err
+		}
+
+		return misc.SaveImage(cmd.Context(), target, image)
+	},
+}
+
+func printHistory(entries []v1.History) error {
+	return render(entries, func() error {
+		table := newTable()
+		setHeader(table,
+			"Entry",
+			"Layer",
+			"Created",
+			"CreatedBy",
+			"Comment",
+			"Author",
+		)
+
+		var layerIdx int
+		for i, entry := range entries {
+			var layer = "(empty)"
+			if !entry.EmptyLayer {
+				layer = fmt.Sprintf("%d", layerIdx)
+				layerIdx++
+			}
+
+			table.Append([]string{
+				fmt.Sprintf("%d", i),
+				layer,
+				entry.Created.String(),
+				entry.CreatedBy,
+				entry.Comment,
+				entry.Author,
+			})
+		}
+
+		table.Render()
+		return nil
+	})
+}
+
+func init() {
+	imageCmd.AddCommand(imageHistoryCmd)
+
+	imageHistoryCmd.Flags().SortFlags = false
+
+	imageHistoryCmd.Flags().BoolVarP(&imageHistoryCmdSettings.edit, "edit", "e", false, "Edit the history interactively")
+	imageHistoryCmd.Flags().StringArrayVar(&imageHistoryCmdSettings.redact, "redact", nil, "Regular expression of text to redact in the history")
+	imageHistoryCmd.Flags().VarP(&imageHistoryCmdSettings.target, "target", "t", "target")
+}
@@ -22,14 +22,24 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/homeport/forklift/pkg/misc"
-	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 )
 
+// layerDetails is the output of the image layers command
+type layerDetails struct {
+	Layer     int     `json:"layer"`
+	Digest    v1.Hash `json:"digest"`
+	Size      int64   `json:"size"`
+	Created   string  `json:"created,omitempty"`
+	CreatedBy string  `json:"createdBy,omitempty"`
+	Comment   string  `json:"comment,omitempty"`
+	Author    string  `json:"author,omitempty"`
+}
+
 var imageLayersCmd = &cobra.Command{
 	Use:          "layers <image-reference>",
 	Args:         cobra.MinimumNArgs(1),
@@ -52,56 +62,59 @@ var imageLayersCmd = &cobra.Command{
 			return err
 		}
 
-		table := tablewriter.NewWriter(os.Stdout)
-		table.SetBorder(false)
-		table.SetHeaderLine(true)
-		table.SetCenterSeparator("┼")
-		table.SetColumnSeparator("│")
-		table.SetRowSeparator("─")
-		table.SetAlignment(tablewriter.ALIGN_LEFT)
-		table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
-		table.SetAutoWrapText(true)
-		table.SetAutoFormatHeaders(false)
-
-		table.SetHeader([]string{
-			"Layer",
-			"Size",
-			"Created",
-			"CreatedBy",
-			"Comment",
-			"Author",
-		})
-
+		var details []layerDetails
 		for _, layer := range layers {
 			if layer.Layer == nil {
 				continue
 			}
 
+			digest, err := layer.Digest()
+			if err != nil {
+				return err
+			}
+
 			size, err := layer.Size()
 			if err != nil {
 				return err
 			}
 
-			var createdBy, comment, author, created string
+			var entry = layerDetails{Layer: *layer.LayerIdx, Digest: digest, Size: size}
 			if layer.History != nil {
-				createdBy = layer.History.CreatedBy
-				comment = layer.History.Comment
-				author = layer.History.Author
-				created = layer.History.Created.String()
+				entry.Created = layer.History.Created.String()
+				entry.CreatedBy = layer.History.CreatedBy
+				entry.Comment = layer.History.Comment
+				entry.Author = layer.History.Author
 			}
 
-			table.Append([]string{
-				fmt.Sprintf("%d", *layer.LayerIdx),
-				humanReadableSize(size),
-				created,
-				createdBy,
-				comment,
-				author,
-			})
+			details = append(details, entry)
 		}
 
-		table.Render()
-		return nil
+		return render(details, func() error {
+			table := newTable()
+
+			setHeader(table,
+				"Layer",
+				"Size",
+				"Created",
+				"CreatedBy",
+				"Comment",
+				"Author",
+			)
+
+			for _, entry := range details {
+				table.Append([]string{
+					fmt.Sprintf("%d", entry.Layer),
+					humanReadableSize(entry.Size),
+					entry.Created,
+					entry.CreatedBy,
+					entry.Comment,
+					entry.Author,
+				})
+			}
+
+			table.Render()
+			return nil
+		})
 	},
 }
 
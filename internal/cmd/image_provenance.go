@@ -0,0 +1,94 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/homeport/forklift/pkg/misc"
+	"github.com/homeport/forklift/pkg/repackage"
+	"github.com/spf13/cobra"
+)
+
+var imageProvenanceCmd = &cobra.Command{
+	Use:          "provenance <image-reference>",
+	Args:         cobra.ExactArgs(1),
+	Short:        "Show how an image was repackaged",
+	Long:         `Shows the provenance recorded by repackage: the source image digest and image ID, the executed plan, and which source layers went into which layer.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ref, err := name.ParseReference(args[0])
+		if err != nil {
+			return err
+		}
+
+		image, err := misc.LoadImage(cmd.Context(), ref)
+		if err != nil {
+			return err
+		}
+
+		provenance, err := repackage.ReadProvenance(image)
+		if err != nil {
+			return err
+		}
+
+		if provenance == nil {
+			return fmt.Errorf("image %s has no repackage provenance", ref)
+		}
+
+		return render(provenance, func() error {
+			if provenance.Source != nil {
+				pout("source: %s\n", provenance.Source)
+			}
+
+			pout("source image ID: %s\n\nplan:\n", provenance.SourceImageID)
+			for _, line := range strings.Split(strings.TrimSpace(provenance.Plan), "\n") {
+				pout("  %s\n", line)
+			}
+
+			pout("\n")
+
+			table := newTable()
+			setHeader(table, "Layer", "DiffID", "Source DiffIDs")
+			for i, layer := range provenance.Layers {
+				var sources = make([]string, len(layer.Sources))
+				for j, source := range layer.Sources {
+					sources[j] = source.String()
+				}
+
+				table.Append([]string{
+					fmt.Sprintf("%d", i),
+					layer.DiffID.String(),
+					strings.Join(sources, "\n"),
+				})
+			}
+
+			table.Render()
+			return nil
+		})
+	},
+}
+
+func init() {
+	imageCmd.AddCommand(imageProvenanceCmd)
+}
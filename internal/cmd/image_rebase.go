@@ -0,0 +1,90 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/homeport/forklift/pkg/misc"
+	"github.com/homeport/forklift/pkg/rebase"
+	"github.com/spf13/cobra"
+)
+
+var rebaseCmdSettings struct {
+	oldBase string
+	newBase string
+	target  tag
+}
+
+var rebaseCmd = &cobra.Command{
+	Use:   "rebase <image-reference>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Rebase an image onto a new base image",
+	Long: `Rebase replaces the layers of the old base image with the layers of the new
+base image. The image config keeps the settings of the image, but takes the
+updated defaults of the new base image where the image does not override them.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ref, err := name.ParseReference(args[0])
+		if err != nil {
+			return err
+		}
+
+		target, err := targetTag(ref, rebaseCmdSettings.target, "rebased")
+		if err != nil {
+			return err
+		}
+
+		image, err := misc.LoadImage(cmd.Context(), ref)
+		if err != nil {
+			return err
+		}
+
+		oldBase, err := loadImage(cmd.Context(), rebaseCmdSettings.oldBase)
+		if err != nil {
+			return err
+		}
+
+		newBase, err := loadImage(cmd.Context(), rebaseCmdSettings.newBase)
+		if err != nil {
+			return err
+		}
+
+		rebasedImage, err := rebase.Image(image, oldBase, newBase)
+		if err != nil {
+			return err
+		}
+
+		return misc.SaveImage(cmd.Context(), target, rebasedImage)
+	},
+}
+
+func init() {
+	imageCmd.AddCommand(rebaseCmd)
+
+	rebaseCmd.Flags().SortFlags = false
+
+	rebaseCmd.Flags().StringVar(&rebaseCmdSettings.oldBase, "old-base", "", "Base image reference the image is currently based on")
+	rebaseCmd.Flags().StringVar(&rebaseCmdSettings.newBase, "new-base", "", "Base image reference the image should be based on")
+	rebaseCmd.Flags().VarP(&rebaseCmdSettings.target, "target", "t", "target")
+
+	_ = rebaseCmd.MarkFlagRequired("old-base")
+	_ = rebaseCmd.MarkFlagRequired("new-base")
+}
@@ -0,0 +1,138 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"compress/gzip"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/homeport/forklift/pkg/misc"
+	"github.com/homeport/forklift/pkg/remove"
+	"github.com/spf13/cobra"
+)
+
+var imageRemovePathCmdSettings struct {
+	whiteout         bool
+	purge            bool
+	tmpDir           string
+	compressionLevel int
+	target           tag
+}
+
+var imageRemovePathCmd = &cobra.Command{
+	Use:   "remove-path <image-reference> <glob>...",
+	Args:  cobra.MinimumNArgs(2),
+	Short: "Remove files or directories from an image",
+	Long: `Removes files or directories matching the glob patterns from an image, where **
+matches any number of path elements.
+
+With --whiteout, a layer with whiteout entries is appended, which is cheap, but
+keeps the content in the existing layer blobs. With --purge, every affected
+layer is rewritten, so that the content is gone from all layer blobs.`,
+	Example:      `  forklift image remove-path myimage:1.0 --purge 'root/.ssh' '**/*.pem'`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateCompressionLevel(imageRemovePathCmdSettings.compressionLevel); err != nil {
+			return err
+		}
+
+		ref, err := name.ParseReference(args[0])
+		if err != nil {
+			return err
+		}
+
+		target, err := targetTag(ref, imageRemovePathCmdSettings.target, "removed")
+		if err != nil {
+			return err
+		}
+
+		image, err := misc.LoadImage(cmd.Context(), ref)
+		if err != nil {
+			return err
+		}
+
+		var patterns = args[1:]
+		var opts = []remove.Option{
+			remove.WithTempDir(imageRemovePathCmdSettings.tmpDir),
+			remove.WithCompressionLevel(imageRemovePathCmdSettings.compressionLevel),
+		}
+
+		switch {
+		case imageRemovePathCmdSettings.whiteout:
+			result, removed, err := remove.Whiteout(image, patterns, opts...)
+			if err != nil {
+				return err
+			}
+
+			defer func() { _ = result.Close() }()
+
+			if len(removed) == 0 {
+				return fmt.Errorf("no paths match %s", strings.Join(patterns, ", "))
+			}
+
+			pinfo("appended whiteout layer for %d paths\n", len(removed))
+			for _, path := range removed {
+				pinfo("  /%s\n", path)
+			}
+
+			return misc.SaveImage(cmd.Context(), target, result)
+
+		default:
+			result, changes, err := remove.Purge(image, patterns, opts...)
+			if err != nil {
+				return err
+			}
+
+			defer func() { _ = result.Close() }()
+
+			if len(changes) == 0 {
+				return fmt.Errorf("no paths match %s", strings.Join(patterns, ", "))
+			}
+
+			pinfo("rewrote %d layers\n", len(changes))
+			for _, change := range changes {
+				pinfo("  layer=%d (%s), removed %d paths\n", change.Index, change.CreatedBy, len(change.Removed))
+				for _, path := range change.Removed {
+					pinfo("    /%s\n", path)
+				}
+			}
+
+			return misc.SaveImage(cmd.Context(), target, result)
+		}
+	},
+}
+
+func init() {
+	imageCmd.AddCommand(imageRemovePathCmd)
+
+	imageRemovePathCmd.Flags().SortFlags = false
+
+	imageRemovePathCmd.Flags().BoolVar(&imageRemovePathCmdSettings.whiteout, "whiteout", false, "Append a layer with whiteout entries")
+	imageRemovePathCmd.Flags().BoolVar(&imageRemovePathCmdSettings.purge, "purge", false, "Rewrite all affected layers")
+	imageRemovePathCmd.Flags().StringVar(&imageRemovePathCmdSettings.tmpDir, "tmp-dir", "", "Directory for temporary files (default is the system temporary directory)")
+	imageRemovePathCmd.Flags().IntVar(&imageRemovePathCmdSettings.compressionLevel, "compression-level", gzip.DefaultCompression, "Gzip compression level of new layers (1 fastest to 9 best, -1 default)")
+	imageRemovePathCmd.Flags().VarP(&imageRemovePathCmdSettings.target, "target", "t", "target")
+
+	imageRemovePathCmd.MarkFlagsMutuallyExclusive("whiteout", "purge")
+	imageRemovePathCmd.MarkFlagsOneRequired("whiteout", "purge")
+}
@@ -21,22 +21,36 @@
 package cmd
 
 import (
-	"bufio"
-	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/homeport/forklift/pkg/attest"
 	"github.com/homeport/forklift/pkg/interactive"
 	"github.com/homeport/forklift/pkg/misc"
 	"github.com/homeport/forklift/pkg/repackage"
+	"github.com/homeport/forklift/pkg/tar"
 	"github.com/spf13/cobra"
 )
 
 var repackageCmdSettings struct {
-	interactive bool
-	target      tag
+	interactive      bool
+	dryRun           bool
+	force            bool
+	provenance       bool
+	attest           string
+	push             bool
+	tmpDir           string
+	base             string
+	target           tag
+	compressionLevel int
 }
 
 // repackageCmd represents the repackage command
@@ -46,18 +60,23 @@ var repackageCmd = &cobra.Command{
 	Short: "Repackage layers of an image",
 	Long:  `Repackage is similar to Git rebase, but for container image layers instead of commits.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateCompressionLevel(repackageCmdSettings.compressionLevel); err != nil {
+			return err
+		}
+
+		// without a plan, the image would only be copied unchanged
+		if !repackageCmdSettings.interactive && !repackageCmdSettings.dryRun && !repackageCmdSettings.force {
+			return errors.New("no plan to repackage, use --interactive to edit the plan, or --force to write the image with all layers picked")
+		}
+
 		ref, err := name.ParseReference(args[0])
 		if err != nil {
 			return err
 		}
 
-		if repackageCmdSettings.target.RegistryStr() == "" {
-			defaultTag, err := name.NewTag(ref.String() + "-repackaged")
-			if err != nil {
-				return err
-			}
-
-			repackageCmdSettings.target = tag{defaultTag}
+		target, err := targetTag(ref, repackageCmdSettings.target, "repackaged")
+		if err != nil {
+			return err
 		}
 
 		image, err := misc.LoadImage(cmd.Context(), ref)
@@ -65,92 +84,368 @@ var repackageCmd = &cobra.Command{
 			return err
 		}
 
+		layers, err := misc.Layers(image)
+		if err != nil {
+			return err
+		}
+
+		boundary, err := baseBoundary(cmd.Context(), image, repackageCmdSettings.base)
+		if err != nil {
+			return err
+		}
+
+		for i := 0; i < boundary; i++ {
+			layers[i].Base = true
+		}
+
+		var plan = repackage.NewPlan(layers)
 		if repackageCmdSettings.interactive {
-			layers, err := misc.Layers(image)
+			text, err := plan.MarshalText()
 			if err != nil {
 				return err
 			}
 
-			// TODO Extract plan to string and string to plan to helper functions
-			var buf bytes.Buffer
-			for i, layer := range layers {
-				var desc string
-				if layer.Layer != nil {
-					diffID, err := layer.DiffID()
-					if err != nil {
-						return err
-					}
+			planText, err := interactive.Edit(string(text))
+			if err != nil {
+				return err
+			}
 
-					desc = diffID.String()
-				}
+			plan, err = repackage.ParsePlan(planText, layers)
+			if err != nil {
+				return err
+			}
+		}
 
-				if desc == "" && layer.History != nil && layer.History.EmptyLayer {
-					desc = "(empty layer)"
-				}
+		if err := plan.Validate(); err != nil {
+			return explain(err, layers)
+		}
 
-				fmt.Fprintf(&buf, "%-6s %3d %s\n", repackage.PICK, i, desc)
-			}
+		conflicts, err := plan.Conflicts()
+		if err != nil {
+			return err
+		}
 
-			planText, err := interactive.Edit(buf.String())
+		if repackageCmdSettings.dryRun {
+			preview, err := repackage.DryRun(plan)
 			if err != nil {
-				return err
+				return explain(err, layers)
 			}
 
-			var plan repackage.Plan
-			var scanner = bufio.NewScanner(strings.NewReader(planText))
-			for scanner.Scan() {
-				parts := strings.Fields(scanner.Text())
-				if len(parts) < 2 {
-					return fmt.Errorf("plan file entry doesn't match the expected format of <intention> <layer>")
-				}
-
-				intent := repackage.Intention(parts[0])
-
-				idx, err := strconv.Atoi(parts[1])
-				if err != nil {
-					return err
-				}
-
-				// TODO Add bound checks
-				layer := layers[idx]
-
-				plan = append(plan, repackage.Action{
-					Intent:  intent,
-					Layer:   layer.Layer,
-					History: layer.History,
-				})
+			return render(newRepackagePreview(plan, preview, conflicts), func() error {
+				printPreview(plan, preview)
+				printConflicts(conflicts)
+				return nil
+			})
+		}
+
+		if len(conflicts) > 0 {
+			printConflicts(conflicts)
+			if !repackageCmdSettings.force {
+				return fmt.Errorf("plan changes the content of %d path(s), use --force to repackage anyway", len(conflicts))
 			}
+		}
+
+		pinfo("repackage plan (%d entries)\n", len(plan))
+		for i := range plan {
+			pinfo("  %s layer=%d (%s)\n",
+				plan[i].Intent,
+				plan[i].OriginalIdx,
+				createdBy(plan[i].History),
+			)
+		}
+
+		var opts = []repackage.Option{
+			repackage.WithTempDir(repackageCmdSettings.tmpDir),
+			repackage.WithCompressionLevel(repackageCmdSettings.compressionLevel),
+		}
 
-			if err := scanner.Err(); err != nil {
+		if !outputCmdSettings.quiet {
+			opts = append(opts, repackage.WithObserver(printProgress))
+		}
+
+		if repackageCmdSettings.provenance || repackageCmdSettings.attest != "" {
+			opts = append(opts, repackage.WithProvenance())
+
+			digest, ok, err := sourceDigest(cmd.Context(), ref, image)
+			switch {
+			case err != nil:
 				return err
-			}
 
-			pout("repackage plan (%d entries)\n", len(plan))
-			for i := range plan {
-				pout("  %s layer=%d (%s)\n",
-					plan[i].Intent,
-					plan[i].OriginalIdx,
-					plan[i].History.CreatedBy,
-				)
+			case ok:
+				opts = append(opts, repackage.WithSourceDigest(digest))
+
+			default:
+				pwarn("the Docker daemon knows no manifest digest of %s, only its image ID is recorded as source\n", ref)
 			}
+		}
 
-			repackagedImage, err := repackage.Image(image, plan)
-			if err != nil {
+		var started = time.Now()
+		repackagedImage, err := repackage.Image(cmd.Context(), image, plan, opts...)
+		if err != nil {
+			return explain(err, layers)
+		}
+
+		defer func() { _ = repackagedImage.Close() }()
+
+		if repackageCmdSettings.push {
+			var location = misc.Location{Scheme: misc.SchemeRegistry, Reference: target}
+			if err := misc.Write(cmd.Context(), location, misc.Artifact{Image: repackagedImage}); err != nil {
 				return err
 			}
 
-			return misc.SaveImage(repackageCmdSettings.target.Tag, repackagedImage)
+		} else if err := misc.SaveImage(cmd.Context(), target, repackagedImage); err != nil {
+			return err
+		}
+
+		if repackageCmdSettings.attest == "" {
+			return nil
+		}
+
+		desc, err := partial.Descriptor(repackagedImage)
+		if err != nil {
+			return err
+		}
+
+		statement := attest.Repackage(target.String(), desc.Digest, ref.String(), repackagedImage.Provenance, started, time.Now())
+		if err := writeJSON(repackageCmdSettings.attest, statement); err != nil {
+			return fmt.Errorf("failed to write attestation: %w", err)
+		}
+
+		if !repackageCmdSettings.push {
+			pwarn("the Docker daemon might not keep the manifest of %s, the attestation only matches the image if it is pushed unchanged, use --push to push both\n", target)
+			return nil
+		}
+
+		referrer, err := attest.Referrer(statement, *desc)
+		if err != nil {
+			return err
 		}
 
+		digest, err := referrer.Digest()
+		if err != nil {
+			return err
+		}
+
+		var location = misc.Location{Scheme: misc.SchemeRegistry, Reference: target.Context().Digest(digest.String())}
+		if err := misc.Write(cmd.Context(), location, misc.Artifact{Image: referrer}); err != nil {
+			return fmt.Errorf("failed to push attestation: %w", err)
+		}
+
+		pinfo("pushed attestation %s\n", location.Reference)
 		return nil
 	},
 }
 
+// sourceDigest returns the manifest digest of the loaded image, the Docker
+// daemon does not keep the manifest of an image, so that the digest of a
+// daemon image is only known if it was pulled from or pushed to its repository
+func sourceDigest(ctx context.Context, ref name.Reference, image v1.Image) (v1.Hash, bool, error) {
+	daemonImage, err := misc.InspectDaemon(ctx, ref)
+	if err != nil {
+		// not served by the Docker daemon (see misc.LoadImage)
+		digest, err := image.Digest()
+		return digest, err == nil, err
+	}
+
+	digest, ok := daemonImage.RepoDigest(ref)
+	return digest, ok, nil
+}
+
+// baseBoundary returns the number of leading history entries that belong to
+// the base image, which is either the explicitly provided one, or the one
+// noted in the image annotations
+func baseBoundary(ctx context.Context, image v1.Image, base string) (int, error) {
+	if base != "" {
+		baseImage, err := loadImage(ctx, base)
+		if err != nil {
+			return 0, err
+		}
+
+		return misc.BaseBoundary(image, baseImage)
+	}
+
+	ref, err := misc.BaseImageReference(image)
+	if missing := (*misc.MissingBaseNameError)(nil); errors.As(err, &missing) {
+		pwarn("%v, base layers are not locked, use --base to provide the base image\n", err)
+		return 0, nil
+	}
+
+	if err != nil || ref == nil {
+		return 0, err
+	}
+
+	baseImage, err := misc.LoadImage(ctx, ref)
+	if err != nil {
+		pwarn("unable to load base image %s noted in the image annotations, base layers are not locked: %v\n", ref, err)
+		return 0, nil
+	}
+
+	boundary, err := misc.BaseBoundary(image, baseImage)
+	if err != nil {
+		pwarn("image does not match base image %s noted in the image annotations, base layers are not locked: %v\n", ref, err)
+		return 0, nil
+	}
+
+	return boundary, nil
+}
+
+// repackagePreview is the result of a dry-run for the json and yaml output
+type repackagePreview struct {
+	Entries   int                      `json:"entries"`
+	Layers    []repackage.PreviewLayer `json:"layers"`
+	Moved     []historyEntry           `json:"moved"`
+	Dropped   []historyEntry           `json:"dropped"`
+	Conflicts []repackage.Conflict     `json:"conflicts"`
+}
+
+// historyEntry is a history entry of the input image
+type historyEntry struct {
+	Layer     int    `json:"layer"`
+	CreatedBy string `json:"createdBy"`
+}
+
+func newRepackagePreview(plan repackage.Plan, preview *repackage.Preview, conflicts []repackage.Conflict) repackagePreview {
+	var entries = func(actions []repackage.Action) []historyEntry {
+		var result = make([]historyEntry, 0, len(actions))
+		for _, action := range actions {
+			result = append(result, historyEntry{Layer: action.OriginalIdx, CreatedBy: createdBy(action.History)})
+		}
+
+		return result
+	}
+
+	var result = repackagePreview{
+		Entries:   len(plan),
+		Layers:    preview.Layers,
+		Moved:     entries(preview.Moved),
+		Dropped:   entries(preview.Dropped),
+		Conflicts: conflicts,
+	}
+
+	if result.Conflicts == nil {
+		result.Conflicts = []repackage.Conflict{}
+	}
+
+	return result
+}
+
+func printPreview(plan repackage.Plan, preview *repackage.Preview) {
+	pout("repackage plan (%d entries) results in %d layers\n", len(plan), len(preview.Layers))
+	for i, layer := range preview.Layers {
+		var size = "empty layer"
+		if !layer.EmptyLayer {
+			size = "~" + humanReadableSize(layer.EstimatedSize)
+		}
+
+		if layer.Locked {
+			size += ", locked"
+		}
+
+		var sources = make([]string, len(layer.Sources))
+		for j, idx := range layer.Sources {
+			sources[j] = strconv.Itoa(idx)
+		}
+
+		pout("  %3d from layer(s) %s (%s)\n", i, strings.Join(sources, ", "), size)
+		pout("      %s\n", layer.CreatedBy)
+	}
+
+	if len(preview.Moved) > 0 {
+		pout("\nmoved history entries\n")
+		for _, action := range preview.Moved {
+			pout("  layer=%d (%s)\n", action.OriginalIdx, createdBy(action.History))
+		}
+	}
+
+	if len(preview.Dropped) > 0 {
+		pout("\ndropped history entries\n")
+		for _, action := range preview.Dropped {
+			pout("  layer=%d (%s)\n", action.OriginalIdx, createdBy(action.History))
+		}
+	}
+}
+
+func printProgress(event repackage.Event) {
+	switch event := event.(type) {
+	case repackage.StageStarted:
+		var sources = make([]string, len(event.Sources))
+		for i, idx := range event.Sources {
+			sources[i] = strconv.Itoa(idx)
+		}
+
+		perr("[%d/%d] layer(s) %s\n", event.Stage+1, event.Stages, strings.Join(sources, ", "))
+
+	case repackage.LayerExtracted:
+		perr("      extracted layer %d (%s)\n", event.Source, humanReadableSize(event.Bytes))
+
+	case repackage.LayerCompressed:
+		perr("      compressed layer %s (%s)\n", event.Digest, humanReadableSize(event.Size))
+
+	case repackage.DigestComputed:
+		perr("image digest %s\n", event.Digest)
+	}
+}
+
+func printConflicts(conflicts []repackage.Conflict) {
+	if len(conflicts) == 0 {
+		return
+	}
+
+	pwarn("\nre-ordered layers change the content of %d path(s)\n", len(conflicts))
+	for _, conflict := range conflicts {
+		pwarn("  %s (layer %d instead of layer %d)\n", conflict.Path, conflict.After, conflict.Before)
+	}
+}
+
+// explain adds the details of the affected layer to plan and layer errors,
+// so that they can be related to the image history
+func explain(err error, layers []misc.Layer) error {
+	var describe = func(idx int) string {
+		if idx < 0 || idx >= len(layers) {
+			return strconv.Itoa(idx)
+		}
+
+		return fmt.Sprintf("%d (%s)", idx, createdBy(layers[idx].History))
+	}
+
+	var planErr *repackage.PlanError
+	var layerErr *repackage.LayerError
+	var entryErr *tar.UnsupportedEntryError
+	switch {
+	case errors.As(err, &layerErr) && errors.As(err, &entryErr):
+		return fmt.Errorf("%w\n  layer %s contains unsupported %s %s", err, describe(layerErr.Index), entryErr.Type, entryErr.Path)
+
+	case errors.As(err, &layerErr):
+		return fmt.Errorf("%w\n  layer %s, digest %s", err, describe(layerErr.Index), layerErr.Digest)
+
+	case errors.As(err, &planErr) && planErr.Action != nil:
+		return fmt.Errorf("%w\n  layer %s", err, describe(planErr.Action.OriginalIdx))
+	}
+
+	return err
+}
+
+func createdBy(history *v1.History) string {
+	if history == nil {
+		return ""
+	}
+
+	return history.CreatedBy
+}
+
 func init() {
 	imageCmd.AddCommand(repackageCmd)
 
 	repackageCmd.Flags().SortFlags = false
 
 	repackageCmd.Flags().BoolVarP(&repackageCmdSettings.interactive, "interactive", "i", false, "Interactively decide on repackaging")
+	repackageCmd.Flags().BoolVar(&repackageCmdSettings.dryRun, "dry-run", false, "Validate the plan and show the resulting layers without repackaging")
+	repackageCmd.Flags().BoolVar(&repackageCmdSettings.force, "force", false, "Repackage even if re-ordered or dropped layers change the content of paths, or without a plan")
+	repackageCmd.Flags().BoolVar(&repackageCmdSettings.provenance, "provenance", false, "Record the source image, plan, and layer mapping in the image labels and annotations (implied by --attest)")
+	repackageCmd.Flags().StringVar(&repackageCmdSettings.attest, "attest", "", "Write an in-toto statement with SLSA provenance for the manifest digest of the repackaged image to the file")
+	repackageCmd.Flags().BoolVar(&repackageCmdSettings.push, "push", false, "Push the repackaged image to the registry instead of the Docker daemon, an attestation is pushed as OCI referrer of the image")
+	repackageCmd.Flags().StringVar(&repackageCmdSettings.tmpDir, "tmp-dir", "", "Directory for temporary files (default is the system temporary directory)")
+	repackageCmd.Flags().IntVar(&repackageCmdSettings.compressionLevel, "compression-level", gzip.DefaultCompression, "Gzip compression level of new layers (1 fastest to 9 best, -1 default)")
+	repackageCmd.Flags().StringVar(&repackageCmdSettings.base, "base", "", "Base image reference, whose layers must stay unchanged")
 	repackageCmd.Flags().VarP(&repackageCmdSettings.target, "target", "t", "target")
 }
@@ -0,0 +1,180 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/homeport/forklift/pkg/secrets"
+	"github.com/spf13/cobra"
+)
+
+var imageScanSecretsCmdSettings struct {
+	rules          string
+	noDefaultRules bool
+	disableRules   []string
+	maxFileSize    int64
+	exitCode       int
+}
+
+var imageScanSecretsCmd = &cobra.Command{
+	Use:   "scan-secrets <image-reference>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Scan all image layers for secrets",
+	Long: `Scans the files of every layer, including files that are deleted in a later
+layer, as well as the image config and history for private keys, tokens, and
+passwords.
+
+Additional rules can be provided in a YAML file with a top-level rules list,
+where each rule has a name, a regex, and an optional description. A rule with
+the name of a built-in rule replaces it.
+
+The command exits with code 3 (--exit-code) if secrets are found, and with
+code 1 if the scan fails. Files larger than --max-file-size are not scanned,
+they are listed after the findings.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var settings = imageScanSecretsCmdSettings
+
+		var rules []secrets.Rule
+		if !settings.noDefaultRules {
+			rules = secrets.DefaultRules()
+		}
+
+		if settings.rules != "" {
+			additional, err := secrets.LoadRules(settings.rules)
+			if err != nil {
+				return err
+			}
+
+			rules = secrets.MergeRules(rules, additional...)
+		}
+
+		rules = slices.DeleteFunc(rules, func(rule secrets.Rule) bool {
+			return slices.Contains(settings.disableRules, rule.Name)
+		})
+
+		if len(rules) == 0 {
+			return fmt.Errorf("no rules configured")
+		}
+
+		scanner, err := secrets.NewScanner(rules)
+		if err != nil {
+			return err
+		}
+
+		scanner.MaxFileSize = settings.maxFileSize
+
+		image, err := loadImage(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+
+		report, err := scanner.Image(image)
+		if err != nil {
+			return err
+		}
+
+		var findings = report.Findings
+		err = render(report, func() error {
+			defer printSkipped(report.Skipped)
+
+			if len(findings) == 0 {
+				pout("no secrets found\n")
+				return nil
+			}
+
+			table := newTable()
+			table.SetAutoWrapText(false)
+
+			setHeader(table,
+				"Layer",
+				"Path",
+				"Line",
+				"Rule",
+				"Match",
+				"Note",
+			)
+
+			for _, finding := range findings {
+				var layer = fmt.Sprintf("%d", finding.Layer)
+				if finding.Layer == secrets.ConfigLayer {
+					layer = "config"
+				}
+
+				var note string
+				if finding.Deleted {
+					note = "deleted in later layer"
+				}
+
+				table.Append([]string{
+					layer,
+					finding.Path,
+					fmt.Sprintf("%d", finding.Line),
+					finding.Rule,
+					finding.Excerpt,
+					note,
+				})
+			}
+
+			table.Render()
+			return nil
+		})
+
+		if err != nil {
+			return err
+		}
+
+		if len(findings) == 0 || settings.exitCode == 0 {
+			return nil
+		}
+
+		return &exitError{
+			code: settings.exitCode,
+			msg:  fmt.Sprintf("found %d potential secrets\n", len(findings)),
+		}
+	},
+}
+
+// printSkipped lists the files that were not scanned due to their size
+func printSkipped(skipped []secrets.Skipped) {
+	if len(skipped) == 0 {
+		return
+	}
+
+	pout("\nskipped %d files larger than %d bytes\n", len(skipped), imageScanSecretsCmdSettings.maxFileSize)
+	for _, file := range skipped {
+		pout("  layer=%d %s (%s)\n", file.Layer, file.Path, humanReadableSize(file.Size))
+	}
+}
+
+func init() {
+	imageCmd.AddCommand(imageScanSecretsCmd)
+
+	imageScanSecretsCmd.Flags().SortFlags = false
+
+	imageScanSecretsCmd.Flags().StringVar(&imageScanSecretsCmdSettings.rules, "rules", "", "YAML file with additional rules")
+	imageScanSecretsCmd.Flags().BoolVar(&imageScanSecretsCmdSettings.noDefaultRules, "no-default-rules", false, "Do not use the built-in rules")
+	imageScanSecretsCmd.Flags().StringArrayVar(&imageScanSecretsCmdSettings.disableRules, "disable-rule", nil, "Name of a rule to disable")
+	imageScanSecretsCmd.Flags().Int64Var(&imageScanSecretsCmdSettings.maxFileSize, "max-file-size", secrets.DefaultMaxFileSize, "Size limit in bytes for files to be scanned")
+	imageScanSecretsCmd.Flags().IntVar(&imageScanSecretsCmdSettings.exitCode, "exit-code", 3, "Exit code to use when secrets are found, 1 is used for failed scans")
+}
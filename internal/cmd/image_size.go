@@ -21,7 +21,6 @@
 package cmd
 
 import (
-	"fmt"
 	"io"
 
 	"github.com/google/go-containerregistry/pkg/name"
@@ -91,14 +90,21 @@ var imageSizeCmd = &cobra.Command{
 			}
 		}
 
-		if imageSizeCmdSettings.humanReadable {
-			fmt.Println(humanReadableSize(size))
+		var result = struct {
+			Size         int64 `json:"size"`
+			Uncompressed bool  `json:"uncompressed"`
+		}{size, imageSizeCmdSettings.uncompressed}
 
-		} else {
-			fmt.Printf("%d\n", size)
-		}
+		return render(result, func() error {
+			if imageSizeCmdSettings.humanReadable {
+				pout("%s\n", humanReadableSize(size))
+
+			} else {
+				pout("%d\n", size)
+			}
 
-		return nil
+			return nil
+		})
 	},
 }
 
@@ -0,0 +1,117 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"compress/gzip"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/homeport/forklift/pkg/misc"
+	"github.com/homeport/forklift/pkg/split"
+	"github.com/spf13/cobra"
+)
+
+var imageSplitLayerCmdSettings struct {
+	paths            []string
+	tmpDir           string
+	compressionLevel int
+	target           tag
+}
+
+var imageSplitLayerCmd = &cobra.Command{
+	Use:   "split-layer <image-reference> <layer>",
+	Args:  cobra.ExactArgs(2),
+	Short: "Split a layer of an image into multiple layers",
+	Long: `Splits a layer into one layer per --path flag, followed by one layer with all
+remaining entries. A --path flag can list multiple glob patterns separated by
+commas, where ** matches any number of path elements. Each entry goes into the
+first layer with a matching pattern. The layer index refers to the history
+entries as shown by the history command.`,
+	Example:      `  forklift image split-layer myimage:1.0 3 --path 'usr/lib/**' --path 'app/**'`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateCompressionLevel(imageSplitLayerCmdSettings.compressionLevel); err != nil {
+			return err
+		}
+
+		ref, err := name.ParseReference(args[0])
+		if err != nil {
+			return err
+		}
+
+		idx, err := strconv.Atoi(args[1])
+		if err != nil {
+			return err
+		}
+
+		target, err := targetTag(ref, imageSplitLayerCmdSettings.target, "split")
+		if err != nil {
+			return err
+		}
+
+		image, err := misc.LoadImage(cmd.Context(), ref)
+		if err != nil {
+			return err
+		}
+
+		var groups [][]string
+		for _, path := range imageSplitLayerCmdSettings.paths {
+			groups = append(groups, strings.Split(path, ","))
+		}
+
+		result, parts, err := split.Image(image, idx, groups,
+			split.WithTempDir(imageSplitLayerCmdSettings.tmpDir),
+			split.WithCompressionLevel(imageSplitLayerCmdSettings.compressionLevel),
+		)
+		if err != nil {
+			return err
+		}
+
+		defer func() { _ = result.Close() }()
+
+		pinfo("split layer %d into %d layers\n", idx, len(parts))
+		for _, part := range parts {
+			var patterns = "remaining entries"
+			if len(part.Patterns) > 0 {
+				patterns = strings.Join(part.Patterns, ", ")
+			}
+
+			pinfo("  %s (%d entries)\n", patterns, part.Entries)
+		}
+
+		return misc.SaveImage(cmd.Context(), target, result)
+	},
+}
+
+func init() {
+	imageCmd.AddCommand(imageSplitLayerCmd)
+
+	imageSplitLayerCmd.Flags().SortFlags = false
+
+	imageSplitLayerCmd.Flags().StringArrayVar(&imageSplitLayerCmdSettings.paths, "path", nil, "Comma separated glob patterns of the entries for one new layer")
+	imageSplitLayerCmd.Flags().StringVar(&imageSplitLayerCmdSettings.tmpDir, "tmp-dir", "", "Directory for temporary files (default is the system temporary directory)")
+	imageSplitLayerCmd.Flags().IntVar(&imageSplitLayerCmdSettings.compressionLevel, "compression-level", gzip.DefaultCompression, "Gzip compression level of new layers (1 fastest to 9 best, -1 default)")
+	imageSplitLayerCmd.Flags().VarP(&imageSplitLayerCmdSettings.target, "target", "t", "target")
+
+	_ = imageSplitLayerCmd.MarkFlagRequired("path")
+}
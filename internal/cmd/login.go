@@ -0,0 +1,147 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/homeport/forklift/pkg/auth"
+	"github.com/homeport/forklift/pkg/misc"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var loginCmd = &cobra.Command{
+	Use:   "login <registry>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Log in to a registry",
+	Long: `Logs in to a registry and stores the credentials in the credential helper
+configured for the registry, or otherwise in the auth file (--authfile, or
+REGISTRY_AUTH_FILE, or the Docker config file).
+
+Use --username and --password-stdin for non-interactive use, missing values
+are prompted for in a terminal.`,
+	Example:      `  echo "$TOKEN" | forklift login ghcr.io --username octocat --password-stdin`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		registry, err := name.NewRegistry(args[0])
+		if err != nil {
+			return err
+		}
+
+		username, password, err := credentials()
+		if err != nil {
+			return err
+		}
+
+		if err := auth.Verify(cmd.Context(), registry, authn.FromConfig(authn.AuthConfig{Username: username, Password: password}), misc.Transport); err != nil {
+			return err
+		}
+
+		file, helper, err := credentialStore(registry)
+		if err != nil {
+			return err
+		}
+
+		if helper != "" {
+			if err := helper.Store(registry.RegistryStr(), username, password); err != nil {
+				return fmt.Errorf("failed to store credentials in docker-credential-%s: %w", helper, err)
+			}
+
+			pinfo("login succeeded, credentials stored in docker-credential-%s\n", helper)
+			return nil
+		}
+
+		file.Set(registry.RegistryStr(), username, password)
+		if err := file.Save(); err != nil {
+			return err
+		}
+
+		pinfo("login succeeded, credentials stored in %s\n", file.Path)
+		return nil
+	},
+}
+
+// credentials returns the username and password from the command-line, or
+// prompts for them if the standard input is a terminal
+func credentials() (string, string, error) {
+	var username, password = authOptions.Username, authOptions.Password
+	if username != "" && password != "" {
+		return username, password, nil
+	}
+
+	var fd = int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", "", fmt.Errorf("no credentials, use --username and --password-stdin")
+	}
+
+	if username == "" {
+		perr("Username: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil {
+			return "", "", err
+		}
+
+		username = strings.TrimSpace(line)
+	}
+
+	if password == "" {
+		perr("Password: ")
+		data, err := term.ReadPassword(fd)
+		perr("\n")
+		if err != nil {
+			return "", "", err
+		}
+
+		password = string(data)
+	}
+
+	return username, password, nil
+}
+
+// credentialStore returns the auth file and the credential helper to use
+// for the registry, the helper is empty if credentials go into the file
+func credentialStore(registry name.Registry) (*auth.File, auth.Helper, error) {
+	var path = authOptions.AuthFile
+	if path == "" {
+		path = auth.DefaultFile()
+	}
+
+	file, err := auth.LoadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if helper := authOptions.Helper(registry.RegistryStr()); helper != "" {
+		return file, helper, nil
+	}
+
+	return file, file.Helper(registry.RegistryStr()), nil
+}
+
+func init() {
+	rootCmd.AddCommand(loginCmd)
+}
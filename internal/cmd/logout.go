@@ -0,0 +1,73 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/spf13/cobra"
+)
+
+var logoutCmd = &cobra.Command{
+	Use:   "logout <registry>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Log out of a registry",
+	Long: `Removes the credentials of a registry from the credential helper configured
+for the registry, or otherwise from the auth file (--authfile, or
+REGISTRY_AUTH_FILE, or the Docker config file).`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		registry, err := name.NewRegistry(args[0])
+		if err != nil {
+			return err
+		}
+
+		file, helper, err := credentialStore(registry)
+		if err != nil {
+			return err
+		}
+
+		if helper != "" {
+			if err := helper.Erase(registry.RegistryStr()); err != nil {
+				return fmt.Errorf("failed to remove credentials from docker-credential-%s: %w", helper, err)
+			}
+
+			pinfo("removed credentials of %s from docker-credential-%s\n", registry, helper)
+			return nil
+		}
+
+		if !file.Remove(registry.RegistryStr()) {
+			return fmt.Errorf("not logged in to %s, no credentials in %s", registry, file.Path)
+		}
+
+		if err := file.Save(); err != nil {
+			return err
+		}
+
+		pinfo("removed credentials of %s from %s\n", registry, file.Path)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(logoutCmd)
+}
@@ -21,6 +21,11 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
+
+	"github.com/homeport/forklift/pkg/lookup"
+	"github.com/homeport/forklift/pkg/misc"
 	"github.com/spf13/cobra"
 )
 
@@ -29,7 +34,123 @@ var lookupCmd = &cobra.Command{
 	Use:     "lookup",
 	Aliases: []string{"look-up"},
 	Short:   "Look-up commands for indexes and images",
-	Long:    `Look-up commands for indexes and images`,
+	Long: `Look-up commands for indexes and images.
+
+References are resolved like the image commands do: a plain reference is
+looked up in the Docker daemon first and then in the registry. Use a scheme
+prefix for a specific source: docker://<reference>, docker-daemon:<reference>,
+oci:<path>[:<tag>], or docker-archive:<path>[:<reference>].`,
+}
+
+// loadArtifact loads the image or image index of the location argument the
+// same way the image commands do, a plain reference is looked up in the
+// Docker daemon first and then in the registry (see misc.ParseLocation)
+func loadArtifact(ctx context.Context, location string) (misc.Artifact, error) {
+	loc, err := misc.ParseLocation(location)
+	if err != nil {
+		return misc.Artifact{}, err
+	}
+
+	return misc.Load(ctx, loc)
+}
+
+// loadManifest loads the image or image index of the location argument with
+// its manifest as-is, images in the Docker daemon are loaded from the registry
+// using the manifest digest the daemon knows (see describeLocation), since an
+// image exported by the daemon has a manifest of its own
+func loadManifest(ctx context.Context, location string) (misc.Artifact, error) {
+	loc, desc, err := resolveLocation(ctx, location)
+	if err != nil {
+		return misc.Artifact{}, err
+	}
+
+	if desc != nil {
+		loc = misc.Location{Scheme: misc.SchemeRegistry, Reference: loc.Reference.Context().Digest(desc.Digest.String())}
+	}
+
+	return misc.Load(ctx, loc)
+}
+
+// describeLocation describes the image or image index of the location
+// argument, images in the Docker daemon are inspected instead of exported,
+// because the daemon does not keep the manifest of an image (unless the
+// containerd image store is used)
+func describeLocation(ctx context.Context, location string) (*lookup.Description, error) {
+	loc, desc, err := resolveLocation(ctx, location)
+	if err != nil || desc != nil {
+		return desc, err
+	}
+
+	artifact, err := misc.Load(ctx, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	desc, err = lookup.Describe(artifact)
+	if err != nil {
+		return nil, err
+	}
+
+	desc.Source = map[misc.Scheme]string{
+		misc.SchemeRegistry: "registry",
+		misc.SchemeOCI:      "oci",
+		misc.SchemeArchive:  "docker-archive",
+	}[loc.Scheme]
+
+	return desc, nil
+}
+
+// resolveLocation parses the location argument, for an image in the Docker
+// daemon with a known manifest digest it returns its description. Without a
+// manifest digest, a plain reference falls back to the registry, and a
+// docker-daemon reference fails, so that an image ID is never mistaken for
+// a manifest digest.
+func resolveLocation(ctx context.Context, location string) (misc.Location, *lookup.Description, error) {
+	loc, err := misc.ParseLocation(location)
+	if err != nil {
+		return misc.Location{}, nil, err
+	}
+
+	switch loc.Scheme {
+	case misc.SchemeAuto, misc.SchemeDaemon:
+		image, err := misc.InspectDaemon(ctx, loc.Reference)
+		switch {
+		case err == nil:
+			desc := lookup.DescribeDaemon(loc.Reference, image)
+			if desc.HasManifestDigest() {
+				misc.Log.Printf("%s served by the Docker daemon", loc.Reference)
+				return loc, desc, nil
+			}
+
+			if loc.Scheme == misc.SchemeDaemon {
+				return misc.Location{}, nil, fmt.Errorf("the Docker daemon knows no manifest digest of %s, only its image ID %s", loc.Reference, image.ID)
+			}
+
+			misc.Log.Printf("the Docker daemon knows no manifest digest of %s, using the registry", loc.Reference)
+
+		case loc.Scheme == misc.SchemeDaemon:
+			return misc.Location{}, nil, err
+		}
+
+		loc.Scheme = misc.SchemeRegistry
+	}
+
+	return loc, nil, nil
+}
+
+// printSource notes where the description comes from, unless it is the
+// manifest in a registry
+func printSource(desc *lookup.Description) {
+	switch desc.Source {
+	case "registry":
+		return
+
+	case "docker-daemon":
+		pwarn("source: Docker daemon, manifest digest\n")
+
+	default:
+		pwarn("source: %s\n", desc.Source)
+	}
 }
 
 func init() {
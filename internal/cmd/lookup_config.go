@@ -0,0 +1,70 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/spf13/cobra"
+)
+
+var lookupConfigCmdSettings struct {
+	platform string
+	raw      bool
+}
+
+var lookupConfigCmd = &cobra.Command{
+	Use:          "config <reference>",
+	Args:         cobra.ExactArgs(1),
+	Short:        "Look-up image config",
+	Long:         `Look-up config of given image reference, for an index the image of the platform is used`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		artifact, err := loadArtifact(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+
+		if artifact.Index != nil {
+			platform, err := v1.ParsePlatform(lookupConfigCmdSettings.platform)
+			if err != nil {
+				return err
+			}
+
+			if artifact, err = artifact.Select(*platform); err != nil {
+				return err
+			}
+		}
+
+		config, err := artifact.Image.RawConfigFile()
+		if err != nil {
+			return err
+		}
+
+		return renderJSON(config, lookupConfigCmdSettings.raw)
+	},
+}
+
+func init() {
+	lookupCmd.AddCommand(lookupConfigCmd)
+
+	lookupConfigCmd.Flags().StringVar(&lookupConfigCmdSettings.platform, "platform", "linux/amd64", "Platform (os/arch[/variant]) of the image to use for an index")
+	lookupConfigCmd.Flags().BoolVar(&lookupConfigCmdSettings.raw, "raw", false, "Print the config as-is, which matches the digest")
+}
@@ -0,0 +1,49 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var lookupDigestCmd = &cobra.Command{
+	Use:          "digest <reference>",
+	Args:         cobra.ExactArgs(1),
+	Short:        "Look-up digest",
+	Long:         `Look-up digest of given index or image reference, for images in the Docker daemon it is the manifest digest the daemon knows for the repository, without one a plain reference is looked up in the registry`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		desc, err := describeLocation(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+
+		return render(desc, func() error {
+			pout("%s\n", desc.Digest)
+			printSource(desc)
+			return nil
+		})
+	},
+}
+
+func init() {
+	lookupCmd.AddCommand(lookupDigestCmd)
+}
@@ -0,0 +1,56 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var lookupManifestCmdSettings struct {
+	raw bool
+}
+
+var lookupManifestCmd = &cobra.Command{
+	Use:          "manifest <reference>",
+	Args:         cobra.ExactArgs(1),
+	Short:        "Look-up manifest",
+	Long:         `Look-up manifest of given index or image reference`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		artifact, err := loadManifest(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+
+		manifest, err := artifact.RawManifest()
+		if err != nil {
+			return err
+		}
+
+		return renderJSON(manifest, lookupManifestCmdSettings.raw)
+	},
+}
+
+func init() {
+	lookupCmd.AddCommand(lookupManifestCmd)
+
+	lookupManifestCmd.Flags().BoolVar(&lookupManifestCmdSettings.raw, "raw", false, "Print the manifest as-is, which matches the digest")
+}
@@ -21,11 +21,6 @@
 package cmd
 
 import (
-	"fmt"
-
-	"github.com/google/go-containerregistry/pkg/name"
-	"github.com/google/go-containerregistry/pkg/v1/remote"
-	"github.com/homeport/forklift/pkg/misc"
 	"github.com/spf13/cobra"
 )
 
@@ -34,27 +29,28 @@ var lookupMediaType = &cobra.Command{
 	Aliases:      []string{"media-type"},
 	Args:         cobra.MinimumNArgs(1),
 	Short:        "Look-up media type",
-	Long:         `Look-up media type of given index or image reference`,
+	Long:         `Look-up media type of given index or image reference, and whether it is an index, a Docker v2 manifest, or an OCI manifest (including its artifact type)`,
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ref, err := name.ParseReference(args[0])
-		if err != nil {
-			return err
-		}
-
-		opts, err := misc.RemoteOptionsFromRef(cmd.Context(), ref)
+		desc, err := describeLocation(cmd.Context(), args[0])
 		if err != nil {
 			return err
 		}
 
-		desc, err := remote.Head(ref, opts...)
-		if err != nil {
-			return err
-		}
+		return render(desc, func() error {
+			if desc.MediaType != "" {
+				pout("%s (%s)\n", desc.MediaType, desc.Kind)
+			} else {
+				pout("%s\n", desc.Kind)
+			}
 
-		fmt.Println(desc.MediaType)
+			if desc.ArtifactType != "" {
+				pout("artifact type: %s\n", desc.ArtifactType)
+			}
 
-		return nil
+			printSource(desc)
+			return nil
+		})
 	},
 }
 
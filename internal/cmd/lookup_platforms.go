@@ -0,0 +1,87 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/spf13/cobra"
+)
+
+var lookupPlatformsCmd = &cobra.Command{
+	Use:          "platforms <reference>",
+	Args:         cobra.ExactArgs(1),
+	Short:        "Look-up platforms",
+	Long:         `Look-up platforms of the images of given index reference`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		artifact, err := loadManifest(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+
+		var manifests []v1.Descriptor
+		if artifact.Index != nil {
+			manifest, err := artifact.Index.IndexManifest()
+			if err != nil {
+				return err
+			}
+
+			manifests = manifest.Manifests
+
+		} else {
+			self, err := partial.Descriptor(artifact.Image)
+			if err != nil {
+				return err
+			}
+
+			configFile, err := artifact.Image.ConfigFile()
+			if err != nil {
+				return err
+			}
+
+			self.Platform = configFile.Platform()
+			manifests = []v1.Descriptor{*self}
+		}
+
+		return render(manifests, func() error {
+			table := newTable()
+			setHeader(table, "Platform", "Digest", "Media Type")
+			for _, manifest := range manifests {
+				var platform = "unknown"
+				if manifest.Platform != nil {
+					platform = manifest.Platform.String()
+				}
+
+				table.Append([]string{platform, manifest.Digest.String(), fmt.Sprint(manifest.MediaType)})
+			}
+
+			table.Render()
+			return nil
+		})
+	},
+}
+
+func init() {
+	lookupCmd.AddCommand(lookupPlatformsCmd)
+}
@@ -0,0 +1,105 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/homeport/forklift/pkg/misc"
+	"github.com/spf13/cobra"
+)
+
+var lookupReferrersCmdSettings struct {
+	artifactType string
+}
+
+var lookupReferrersCmd = &cobra.Command{
+	Use:   "referrers <reference>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Look-up referrers",
+	Long: `Look-up referrers (i.e. signatures, SBOMs, attestations) of given index or
+image reference using the OCI 1.1 referrers API, with a fallback to the
+referrers tag schema for registries without support for it.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ref, err := name.ParseReference(args[0])
+		if err != nil {
+			return err
+		}
+
+		index, err := misc.FromEndpoints(cmd.Context(), ref, func(ref name.Reference) (v1.ImageIndex, error) {
+			opts, err := misc.RemoteOptionsFromRef(cmd.Context(), ref)
+			if err != nil {
+				return nil, err
+			}
+
+			digest, ok := ref.(name.Digest)
+			if !ok {
+				desc, err := remote.Head(ref, opts...)
+				if err != nil {
+					return nil, err
+				}
+
+				digest = ref.Context().Digest(desc.Digest.String())
+			}
+
+			if lookupReferrersCmdSettings.artifactType != "" {
+				opts = append(opts, remote.WithFilter("artifactType", lookupReferrersCmdSettings.artifactType))
+			}
+
+			return remote.Referrers(digest, opts...)
+		})
+
+		if err != nil {
+			return err
+		}
+
+		manifest, err := index.IndexManifest()
+		if err != nil {
+			return err
+		}
+
+		return render(manifest.Manifests, func() error {
+			table := newTable()
+			setHeader(table, "Digest", "Artifact Type", "Media Type", "Size")
+			for _, desc := range manifest.Manifests {
+				table.Append([]string{
+					desc.Digest.String(),
+					desc.ArtifactType,
+					fmt.Sprint(desc.MediaType),
+					humanReadableSize(desc.Size),
+				})
+			}
+
+			table.Render()
+			return nil
+		})
+	},
+}
+
+func init() {
+	lookupCmd.AddCommand(lookupReferrersCmd)
+
+	lookupReferrersCmd.Flags().StringVar(&lookupReferrersCmdSettings.artifactType, "artifact-type", "", "Only list referrers of the artifact type")
+}
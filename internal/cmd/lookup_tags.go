@@ -0,0 +1,89 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/homeport/forklift/pkg/lookup"
+	"github.com/homeport/forklift/pkg/misc"
+	"github.com/spf13/cobra"
+)
+
+var lookupTagsCmdSettings struct {
+	filter string
+	semver bool
+}
+
+var lookupTagsCmd = &cobra.Command{
+	Use:          "tags <repository>",
+	Args:         cobra.ExactArgs(1),
+	Short:        "Look-up tags",
+	Long:         `Look-up tags of given repository`,
+	Example:      `  forklift lookup tags alpine --filter '^3\.\d+$' --semver`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repo, err := name.NewRepository(args[0])
+		if err != nil {
+			return err
+		}
+
+		tags, err := misc.FromEndpoints(cmd.Context(), repo.Tag("latest"), func(ref name.Reference) ([]string, error) {
+			opts, err := misc.RemoteOptionsFromRef(cmd.Context(), ref)
+			if err != nil {
+				return nil, err
+			}
+
+			return remote.List(ref.Context(), opts...)
+		})
+
+		if err != nil {
+			return err
+		}
+
+		if lookupTagsCmdSettings.filter != "" {
+			if tags, err = lookup.FilterTags(tags, lookupTagsCmdSettings.filter); err != nil {
+				return err
+			}
+		}
+
+		if lookupTagsCmdSettings.semver {
+			lookup.SortTags(tags)
+		}
+
+		return render(tags, func() error {
+			for _, tag := range tags {
+				pout("%s\n", tag)
+			}
+
+			return nil
+		})
+	},
+}
+
+func init() {
+	lookupCmd.AddCommand(lookupTagsCmd)
+
+	lookupTagsCmd.Flags().SortFlags = false
+
+	lookupTagsCmd.Flags().StringVar(&lookupTagsCmdSettings.filter, "filter", "", "Only list tags matching the regular expression")
+	lookupTagsCmd.Flags().BoolVar(&lookupTagsCmdSettings.semver, "semver", false, "Sort semantic version tags by version, followed by all other tags")
+}
@@ -0,0 +1,245 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"go.yaml.in/yaml/v3"
+	"golang.org/x/term"
+)
+
+var outputCmdSettings struct {
+	file    string
+	format  string
+	noColor bool
+	quiet   bool
+}
+
+// out is where commands write their output to, standard output unless
+// --output-file is used
+var out io.Writer = os.Stdout
+
+// closeOutput closes the output file, if there is one
+var closeOutput = func() error { return nil }
+
+// setupOutput validates the output format and opens the output file
+func setupOutput(_ *cobra.Command) error {
+	switch outputCmdSettings.format {
+	case "", "json", "yaml", "table", "text":
+	default:
+		return fmt.Errorf("unsupported output format %q, use json, yaml, table, or text", outputCmdSettings.format)
+	}
+
+	if outputCmdSettings.file == "" || outputCmdSettings.file == "-" {
+		return nil
+	}
+
+	file, err := os.Create(outputCmdSettings.file)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+
+	out, closeOutput = file, file.Close
+	return nil
+}
+
+// render writes the value in the selected output format, the text function
+// writes the default representation used for the table and text formats
+func render(v any, text func() error) error {
+	if value := reflect.ValueOf(v); value.Kind() == reflect.Slice && value.IsNil() {
+		// an empty list instead of null
+		v = []any{}
+	}
+
+	switch outputCmdSettings.format {
+	case "json":
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		pout("%s\n", data)
+		return nil
+
+	case "yaml":
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+
+		yml, err := jsonToYAML(data)
+		if err != nil {
+			return err
+		}
+
+		pout("%s", yml)
+		return nil
+
+	default:
+		return text()
+	}
+}
+
+// renderJSON writes JSON data, as YAML when the yaml output format is
+// selected and otherwise indented unless raw is set
+func renderJSON(data []byte, raw bool) error {
+	if raw {
+		_, err := out.Write(data)
+		return err
+	}
+
+	if outputCmdSettings.format == "yaml" {
+		yml, err := jsonToYAML(data)
+		if err != nil {
+			return err
+		}
+
+		pout("%s", yml)
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		return err
+	}
+
+	pout("%s\n", buf.String())
+	return nil
+}
+
+// jsonToYAML converts JSON to YAML keeping the order of the keys
+func jsonToYAML(data []byte) ([]byte, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+
+	var reset func(*yaml.Node)
+	reset = func(node *yaml.Node) {
+		node.Style = 0
+		for _, child := range node.Content {
+			reset(child)
+		}
+	}
+
+	reset(&node)
+
+	var buf bytes.Buffer
+	var encoder = yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), encoder.Close()
+}
+
+// newTable creates a table writer with the common table style, or a plain
+// table without separators for the text output format
+func newTable() *tablewriter.Table {
+	table := tablewriter.NewWriter(out)
+	table.SetBorder(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAutoWrapText(true)
+	table.SetAutoFormatHeaders(false)
+
+	if outputCmdSettings.format == "text" {
+		table.SetHeaderLine(false)
+		table.SetNoWhiteSpace(true)
+		table.SetTablePadding("   ")
+		return table
+	}
+
+	table.SetHeaderLine(true)
+	table.SetCenterSeparator("┼")
+	table.SetColumnSeparator("│")
+	table.SetRowSeparator("─")
+
+	return table
+}
+
+// setHeader sets the table header, which is bold if colors are enabled
+func setHeader(table *tablewriter.Table, header ...string) {
+	table.SetHeader(header)
+
+	if colored() {
+		var colors = make([]tablewriter.Colors, len(header))
+		for i := range colors {
+			colors[i] = tablewriter.Colors{tablewriter.Bold}
+		}
+
+		table.SetHeaderColor(colors...)
+	}
+}
+
+// colored returns whether the output can use colors, which is the case for
+// a terminal unless --no-color or the NO_COLOR environment variable is set
+func colored() bool {
+	if outputCmdSettings.noColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+
+	file, ok := out.(*os.File)
+	return ok && term.IsTerminal(int(file.Fd()))
+}
+
+// pout writes the output of the command
+func pout(format string, a ...any) {
+	_, _ = fmt.Fprintf(out, format, a...)
+}
+
+// pinfo writes status messages to standard error, which are omitted with
+// --quiet or when a structured output format is selected
+func pinfo(format string, a ...any) {
+	switch {
+	case outputCmdSettings.quiet:
+	case outputCmdSettings.format == "json", outputCmdSettings.format == "yaml":
+	default:
+		perr(format, a...)
+	}
+}
+
+// pwarn writes warnings to standard error, which are omitted with --quiet
+func pwarn(format string, a ...any) {
+	if !outputCmdSettings.quiet {
+		perr(format, a...)
+	}
+}
+
+func perr(format string, a ...any) {
+	_, _ = fmt.Fprintf(os.Stderr, format, a...)
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVarP(&outputCmdSettings.format, "output", "o", "", "Output format: json, yaml, table, or text (default depends on the command)")
+	rootCmd.PersistentFlags().StringVar(&outputCmdSettings.file, "output-file", "", "Write the output to the file instead of standard output")
+	rootCmd.PersistentFlags().BoolVar(&outputCmdSettings.noColor, "no-color", false, "Disable colors in the output (also NO_COLOR)")
+	rootCmd.PersistentFlags().BoolVarP(&outputCmdSettings.quiet, "quiet", "q", false, "Only write results, no status messages")
+}
@@ -0,0 +1,248 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/homeport/forklift/pkg/auth"
+	"github.com/homeport/forklift/pkg/misc"
+	"github.com/homeport/forklift/pkg/registries"
+	"github.com/spf13/cobra"
+)
+
+var registryCmdSettings struct {
+	username          string
+	passwordStdin     bool
+	registryToken     string
+	authFile          string
+	credentialHelpers map[string]string
+
+	insecureRegistries []string
+	caCerts            []string
+	clientCert         string
+	clientKey          string
+	proxy              string
+	config             string
+
+	retries        int
+	retryBackoff   time.Duration
+	retryMaxWait   time.Duration
+	requestTimeout time.Duration
+}
+
+// authOptions are the registry credential settings from the command-line
+// flags and environment variables
+var authOptions auth.Options
+
+// setupRegistry configures how commands authenticate with registries, flags
+// take precedence over the FORKLIFT_* environment variables
+func setupRegistry(cmd *cobra.Command, args []string) error {
+	var flags = cmd.Flags()
+	var setting = func(flag string, value string, env string) string {
+		if flags.Changed(flag) {
+			return value
+		}
+
+		return os.Getenv(env)
+	}
+
+	usernameRegistry, username := scoped(setting("username", registryCmdSettings.username, "FORKLIFT_USERNAME"))
+	tokenRegistry, token := scoped(setting("registry-token", registryCmdSettings.registryToken, "FORKLIFT_REGISTRY_TOKEN"))
+
+	authOptions = auth.Options{
+		Username:      username,
+		Password:      os.Getenv("FORKLIFT_PASSWORD"),
+		RegistryToken: token,
+		AuthFile:      setting("authfile", registryCmdSettings.authFile, "FORKLIFT_AUTHFILE"),
+		Helpers:       map[string]auth.Helper{},
+	}
+
+	for _, registry := range []string{usernameRegistry, tokenRegistry} {
+		if registry != "" {
+			authOptions.Registries = append(authOptions.Registries, registry)
+		}
+	}
+
+	if len(authOptions.Registries) == 0 {
+		authOptions.Registries = referencedRegistries(args)
+	}
+
+	for registry, helper := range registryCmdSettings.credentialHelpers {
+		authOptions.Helpers[registry] = auth.Helper(helper)
+	}
+
+	if registryCmdSettings.passwordStdin {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read password from standard input: %w", err)
+		}
+
+		authOptions.Password = strings.TrimRight(string(data), "\r\n")
+	}
+
+	switch {
+	case authOptions.Password != "" && authOptions.Username == "":
+		return errors.New("a password requires a username, use --username or FORKLIFT_USERNAME")
+
+	case authOptions.Username != "" && authOptions.Password == "" && cmd != loginCmd:
+		return errors.New("a username requires a password, use --password-stdin or FORKLIFT_PASSWORD")
+	}
+
+	if authOptions.Username != "" || authOptions.RegistryToken != "" {
+		misc.Log.Printf("using the explicit credentials for %s", strings.Join(authOptions.Registries, ", "))
+	}
+
+	keychain, err := auth.Keychain(authOptions)
+	if err != nil {
+		return err
+	}
+
+	config, err := registryConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	transport, err := registries.NewTransport(config,
+		registries.WithRetry(registries.Retry{
+			Retries: registryCmdSettings.retries,
+			Backoff: registryCmdSettings.retryBackoff,
+			MaxWait: registryCmdSettings.retryMaxWait,
+		}),
+		registries.WithRequestTimeout(registryCmdSettings.requestTimeout),
+		registries.WithLog(misc.Log),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	misc.Keychain = keychain
+	misc.Transport = transport
+	misc.Mirrors = config.Mirrors
+	return nil
+}
+
+// scoped splits a credential setting of the form [registry=]value, the part
+// before the equal sign is only a registry if it looks like a host name, so
+// that values with equal signs (i.e. base64 padding) are kept as-is
+func scoped(setting string) (string, string) {
+	registry, value, found := strings.Cut(setting, "=")
+	if !found || (!strings.ContainsAny(registry, ".:") && registry != "localhost") {
+		return "", setting
+	}
+
+	return registry, value
+}
+
+// referencedRegistries returns the registries of the image references in
+// the command arguments, which explicit credentials without registry are
+// limited to
+func referencedRegistries(args []string) []string {
+	var result []string
+	for _, arg := range args {
+		location, err := misc.ParseLocation(arg)
+		if err != nil || (location.Scheme != misc.SchemeAuto && location.Scheme != misc.SchemeRegistry) {
+			continue
+		}
+
+		if registry := location.Reference.Context().RegistryStr(); !slices.Contains(result, registry) {
+			result = append(result, registry)
+		}
+	}
+
+	return result
+}
+
+// registryConfig loads the registry config file, if there is one, and
+// applies the connection flags to it, which take precedence over the file
+func registryConfig(cmd *cobra.Command) (*registries.Config, error) {
+	var config = &registries.Config{}
+
+	var filename = registryCmdSettings.config
+	if !cmd.Flags().Changed("registries-config") {
+		if env, ok := os.LookupEnv("FORKLIFT_REGISTRIES_CONFIG"); ok {
+			filename = env
+		}
+	}
+
+	switch {
+	case filename != "":
+		var err error
+		if config, err = registries.LoadConfig(filename); err != nil {
+			return nil, err
+		}
+
+	default:
+		if filename = registries.DefaultConfigFile(); filename != "" {
+			loaded, err := registries.LoadConfig(filename)
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return nil, err
+			}
+
+			if loaded != nil {
+				config = loaded
+			}
+		}
+	}
+
+	for _, host := range registryCmdSettings.insecureRegistries {
+		config.SetInsecure(host)
+	}
+
+	config.Default.CACerts = append(config.Default.CACerts, registryCmdSettings.caCerts...)
+
+	if registryCmdSettings.clientCert != "" || registryCmdSettings.clientKey != "" {
+		config.Default.ClientCert = registryCmdSettings.clientCert
+		config.Default.ClientKey = registryCmdSettings.clientKey
+	}
+
+	if registryCmdSettings.proxy != "" {
+		config.Default.Proxy = registryCmdSettings.proxy
+	}
+
+	return config, nil
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&registryCmdSettings.username, "username", "", "Registry username as [registry=]username, without registry it is used for the registries of the image references of the command (env FORKLIFT_USERNAME)")
+	rootCmd.PersistentFlags().BoolVar(&registryCmdSettings.passwordStdin, "password-stdin", false, "Read the registry password from standard input (env FORKLIFT_PASSWORD)")
+	rootCmd.PersistentFlags().StringVar(&registryCmdSettings.registryToken, "registry-token", "", "Registry bearer token as [registry=]token, without registry it is used for the registries of the image references of the command (env FORKLIFT_REGISTRY_TOKEN)")
+	rootCmd.PersistentFlags().StringVar(&registryCmdSettings.authFile, "authfile", "", "Docker config.json or Podman auth.json file with registry credentials (env FORKLIFT_AUTHFILE)")
+	rootCmd.PersistentFlags().StringToStringVar(&registryCmdSettings.credentialHelpers, "credential-helper", nil, "Credential helper to use for a registry, for example ghcr.io=pass for docker-credential-pass")
+	rootCmd.PersistentFlags().StringArrayVar(&registryCmdSettings.insecureRegistries, "insecure-registry", nil, "Registry host to access using plain HTTP or without TLS certificate verification")
+	rootCmd.PersistentFlags().StringArrayVar(&registryCmdSettings.caCerts, "ca-cert", nil, "PEM file with additional CA certificates to trust for registries")
+	rootCmd.PersistentFlags().StringVar(&registryCmdSettings.clientCert, "client-cert", "", "PEM file with the TLS client certificate for registries")
+	rootCmd.PersistentFlags().StringVar(&registryCmdSettings.clientKey, "client-key", "", "PEM file with the TLS client key for registries")
+	rootCmd.PersistentFlags().StringVar(&registryCmdSettings.proxy, "proxy", "", "Proxy URL for registries (default from HTTPS_PROXY, HTTP_PROXY, NO_PROXY)")
+	rootCmd.PersistentFlags().StringVar(&registryCmdSettings.config, "registries-config", "", "YAML file with settings per registry host and mirror rules (env FORKLIFT_REGISTRIES_CONFIG, default in the user config directory)")
+	rootCmd.PersistentFlags().IntVar(&registryCmdSettings.retries, "retries", registries.DefaultRetry.Retries, "Number of retries of registry requests that failed temporarily")
+	rootCmd.PersistentFlags().DurationVar(&registryCmdSettings.retryBackoff, "retry-backoff", registries.DefaultRetry.Backoff, "Wait time before the first retry, doubled for every further retry")
+	rootCmd.PersistentFlags().DurationVar(&registryCmdSettings.retryMaxWait, "retry-max-wait", registries.DefaultRetry.MaxWait, "Longest wait time before a retry, including waits requested by the registry (Retry-After)")
+	rootCmd.PersistentFlags().DurationVar(&registryCmdSettings.requestTimeout, "request-timeout", 0, "Time to wait for the response of a registry to a single request (0 means no limit)")
+}
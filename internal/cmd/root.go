@@ -21,9 +21,16 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
+	"time"
 
+	"github.com/homeport/forklift/pkg/misc"
 	"github.com/spf13/cobra"
 )
 
@@ -41,11 +48,77 @@ var rootCmd = &cobra.Command{
 	Use:   "forklift",
 	Short: "Experimental tool to manipulate container images",
 	Long:  `Experimental tool to manipulate container images in the terminal.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if rootCmdSettings.verbose {
+			misc.Log.SetOutput(os.Stderr)
+		}
+
+		if rootCmdSettings.timeout > 0 {
+			ctx, cancel := context.WithTimeout(cmd.Context(), rootCmdSettings.timeout)
+			cmd.SetContext(ctx)
+			stopTimeout = cancel
+		}
+
+		if err := setupOutput(cmd); err != nil {
+			return err
+		}
+
+		setupCache(cmd)
+		return setupRegistry(cmd, args)
+	},
 }
 
-func Execute() error { return rootCmd.Execute() }
+// stopTimeout releases the timeout of the command context, if there is one
+var stopTimeout = func() {}
+
+// Execute runs the root command, an interrupt or the timeout cancels the
+// command context so that commands can stop and clean up
+func Execute() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := rootCmd.ExecuteContext(ctx)
+	stopTimeout()
+
+	if closeErr := closeOutput(); closeErr != nil && err == nil {
+		err = fmt.Errorf("failed to write output file: %w", closeErr)
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) && rootCmdSettings.timeout > 0 {
+		return fmt.Errorf("command did not finish within %s (--timeout): %w", rootCmdSettings.timeout, err)
+	}
+
+	return err
+}
+
+// ExitCode returns the exit code to be used for the error returned by
+// Execute, which is 1 unless the command requested a specific one
+func ExitCode(err error) int {
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+
+	return 1
+}
+
+// exitError is an error for which the command requests a specific exit code
+type exitError struct {
+	code int
+	msg  string
+}
+
+func (e *exitError) Error() string { return e.msg }
+
+var rootCmdSettings struct {
+	verbose bool
+	timeout time.Duration
+}
 
 func init() {
 	rootCmd.Flags().SortFlags = false
 	rootCmd.PersistentFlags().SortFlags = false
+
+	rootCmd.PersistentFlags().BoolVarP(&rootCmdSettings.verbose, "verbose", "v", false, "Log details, for example which registry endpoint served an image")
+	rootCmd.PersistentFlags().DurationVar(&rootCmdSettings.timeout, "timeout", 0, "Time limit for the whole command (0 means no limit)")
 }
@@ -21,8 +21,6 @@
 package cmd
 
 import (
-	"fmt"
-
 	"github.com/spf13/cobra"
 )
 
@@ -35,12 +33,19 @@ var versionCmd = &cobra.Command{
 	Args:  cobra.MaximumNArgs(0),
 	Short: "Shows the version of this tool",
 	Long:  `Shows the version of this tool`,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(version) == 0 {
 			version = "(development)"
 		}
 
-		fmt.Printf("%s version %s\n", executableName, version)
+		var result = struct {
+			Version string `json:"version"`
+		}{version}
+
+		return render(result, func() error {
+			pout("%s version %s\n", executableName, version)
+			return nil
+		})
 	},
 }
 
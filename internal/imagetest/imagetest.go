@@ -0,0 +1,155 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package imagetest
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+)
+
+// Image creates an in-memory image with one layer per list of files (see Layer)
+func Image(layers ...[]string) v1.Image {
+	GinkgoHelper()
+
+	var result = empty.Image
+	for i, files := range layers {
+		var err error
+		result, err = mutate.Append(result, mutate.Addendum{
+			Layer:   Layer(files...),
+			History: v1.History{CreatedBy: "layer " + string(rune('a'+i))},
+		})
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	return result
+}
+
+// Layer creates an in-memory layer with the given files, which have their
+// name as content, names ending with a slash are directories
+func Layer(files ...string) v1.Layer {
+	GinkgoHelper()
+
+	var headers = make([]*tar.Header, 0, len(files))
+	for _, name := range files {
+		var header = &tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0644}
+		if strings.HasSuffix(name, "/") {
+			header = &tar.Header{Typeflag: tar.TypeDir, Name: name, Mode: 0755}
+		}
+
+		headers = append(headers, header)
+	}
+
+	return LayerOf(headers...)
+}
+
+// LayerOf creates an in-memory layer with the given entries, regular files
+// have their name as content
+func LayerOf(headers ...*tar.Header) v1.Layer {
+	GinkgoHelper()
+
+	var contents = make([]string, len(headers))
+	for i, header := range headers {
+		if header.Typeflag == tar.TypeReg {
+			contents[i] = header.Name
+		}
+	}
+
+	return layer(headers, contents)
+}
+
+// LayerWithContent creates an in-memory layer with the given files and
+// their contents
+func LayerWithContent(files map[string]string) v1.Layer {
+	GinkgoHelper()
+
+	var headers = make([]*tar.Header, 0, len(files))
+	var contents = make([]string, 0, len(files))
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		headers = append(headers, &tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0644})
+		contents = append(contents, files[name])
+	}
+
+	return layer(headers, contents)
+}
+
+// Must fails the spec on error and returns the value otherwise
+func Must[T any](value T, err error) T {
+	GinkgoHelper()
+
+	Expect(err).ToNot(HaveOccurred())
+	return value
+}
+
+func layer(headers []*tar.Header, contents []string) v1.Layer {
+	GinkgoHelper()
+
+	var buf bytes.Buffer
+	var tw = tar.NewWriter(&buf)
+	for i, header := range headers {
+		header.Size = int64(len(contents[i]))
+		Expect(tw.WriteHeader(header)).To(Succeed())
+		_, err := tw.Write([]byte(contents[i]))
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	Expect(tw.Close()).To(Succeed())
+
+	var data = buf.Bytes()
+	result, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	})
+	Expect(err).ToNot(HaveOccurred())
+
+	return result
+}
+
+// Files lists the entry names of the layer
+func Files(layer v1.Layer) []string {
+	GinkgoHelper()
+
+	rc, err := layer.Uncompressed()
+	Expect(err).ToNot(HaveOccurred())
+	defer func() { _ = rc.Close() }()
+
+	var result []string
+	var tr = tar.NewReader(rc)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return result
+		}
+
+		Expect(err).ToNot(HaveOccurred())
+		result = append(result, header.Name)
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package attest
+
+import (
+	"time"
+
+	"github.com/homeport/forklift/pkg/repackage"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+const (
+	// StatementType is the in-toto statement type
+	StatementType = "https://in-toto.io/Statement/v0.1"
+
+	// PredicateType is the SLSA provenance predicate type
+	PredicateType = "https://slsa.dev/provenance/v0.2"
+
+	// BuilderID identifies forklift as the builder
+	BuilderID = "https://github.com/homeport/forklift"
+
+	// RepackageBuildType describes a repackage run, the parameters are the
+	// plan and the layer mapping
+	RepackageBuildType = "https://github.com/homeport/forklift/repackage@v1"
+)
+
+// Statement is an in-toto statement with a SLSA provenance predicate
+type Statement struct {
+	Type          string     `json:"_type"`
+	PredicateType string     `json:"predicateType"`
+	Subject       []Subject  `json:"subject"`
+	Predicate     Provenance `json:"predicate"`
+}
+
+// Subject is an artifact the statement is about
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// Provenance is the SLSA provenance predicate
+type Provenance struct {
+	Builder    Builder    `json:"builder"`
+	BuildType  string     `json:"buildType"`
+	Invocation Invocation `json:"invocation"`
+	Metadata   Metadata   `json:"metadata"`
+	Materials  []Material `json:"materials"`
+}
+
+// Builder identifies the entity that executed the build
+type Builder struct {
+	ID string `json:"id"`
+}
+
+// Invocation describes the parameters of the build
+type Invocation struct {
+	Parameters any `json:"parameters"`
+}
+
+// Metadata describes when the build ran
+type Metadata struct {
+	BuildStartedOn  *time.Time `json:"buildStartedOn,omitempty"`
+	BuildFinishedOn *time.Time `json:"buildFinishedOn,omitempty"`
+	Reproducible    bool       `json:"reproducible"`
+}
+
+// Material is an input of the build
+type Material struct {
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest,omitempty"`
+}
+
+// RepackageParameters are the invocation parameters of a repackage run
+type RepackageParameters struct {
+	Plan   string                      `json:"plan"`
+	Layers []repackage.LayerProvenance `json:"layers"`
+}
+
+// Repackage creates the statement for a repackaged image, where subject and
+// source are the names of the resulting and the input image, and the digest
+// is the manifest digest of the resulting image
+func Repackage(subject string, digest v1.Hash, source string, provenance *repackage.Provenance, started, finished time.Time) Statement {
+	// the source is listed without digest if its manifest digest is unknown
+	var material = Material{URI: source}
+	if provenance.Source != nil {
+		material.Digest = digestSet(*provenance.Source)
+	}
+
+	return Statement{
+		Type:          StatementType,
+		PredicateType: PredicateType,
+		Subject:       []Subject{{Name: subject, Digest: digestSet(digest)}},
+		Predicate: Provenance{
+			Builder:   Builder{ID: BuilderID},
+			BuildType: RepackageBuildType,
+			Invocation: Invocation{
+				Parameters: RepackageParameters{
+					Plan:   provenance.Plan,
+					Layers: provenance.Layers,
+				},
+			},
+			Metadata: Metadata{
+				BuildStartedOn:  utc(started),
+				BuildFinishedOn: utc(finished),
+				Reproducible:    false,
+			},
+			Materials: []Material{material},
+		},
+	}
+}
+
+func digestSet(hash v1.Hash) map[string]string {
+	return map[string]string{hash.Algorithm: hash.Hex}
+}
+
+func utc(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	t = t.UTC()
+	return &t
+}
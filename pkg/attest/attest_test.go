@@ -0,0 +1,107 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package attest_test
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/homeport/forklift/pkg/attest"
+	"github.com/homeport/forklift/pkg/repackage"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+var _ = Describe("Attest", func() {
+	It("should create a SLSA provenance statement for a repackaged image", func() {
+		var hash = func(hex string) v1.Hash {
+			return v1.Hash{Algorithm: "sha256", Hex: hex}
+		}
+
+		var source = hash("aaaa")
+		provenance := &repackage.Provenance{
+			Source: &source,
+			Plan:   "pick     0 sha256:cccc\n",
+			Layers: []repackage.LayerProvenance{{DiffID: hash("cccc"), Sources: []v1.Hash{hash("cccc")}}},
+		}
+
+		started := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+		statement := attest.Repackage("image:repackaged", hash("bbbb"), "image:latest", provenance, started, started.Add(time.Minute))
+
+		data, err := json.Marshal(statement)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(MatchJSON(`{
+			"_type": "https://in-toto.io/Statement/v0.1",
+			"predicateType": "https://slsa.dev/provenance/v0.2",
+			"subject": [{"name": "image:repackaged", "digest": {"sha256": "bbbb"}}],
+			"predicate": {
+				"builder": {"id": "https://github.com/homeport/forklift"},
+				"buildType": "https://github.com/homeport/forklift/repackage@v1",
+				"invocation": {
+					"parameters": {
+						"plan": "pick     0 sha256:cccc\n",
+						"layers": [{"diffID": "sha256:cccc", "sources": ["sha256:cccc"]}]
+					}
+				},
+				"metadata": {
+					"buildStartedOn": "2025-01-01T12:00:00Z",
+					"buildFinishedOn": "2025-01-01T12:01:00Z",
+					"reproducible": false
+				},
+				"materials": [{"uri": "image:latest", "digest": {"sha256": "aaaa"}}]
+			}
+		}`))
+	})
+
+	It("should create an OCI artifact that refers to the image", func() {
+		var subject = v1.Descriptor{
+			MediaType: types.OCIManifestSchema1,
+			Size:      1234,
+			Digest:    v1.Hash{Algorithm: "sha256", Hex: strings.Repeat("b", 64)},
+		}
+
+		referrer, err := attest.Referrer(attest.Statement{Type: attest.StatementType}, subject)
+		Expect(err).ToNot(HaveOccurred())
+
+		manifest, err := referrer.Manifest()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(manifest.ArtifactType).To(Equal(string(attest.StatementMediaType)))
+		Expect(manifest.Subject).To(Equal(&subject))
+		Expect(manifest.Layers).To(HaveLen(1))
+		Expect(manifest.Layers[0].MediaType).To(Equal(attest.StatementMediaType))
+
+		layers, err := referrer.Layers()
+		Expect(err).ToNot(HaveOccurred())
+
+		rc, err := layers[0].Compressed()
+		Expect(err).ToNot(HaveOccurred())
+		defer func() { _ = rc.Close() }()
+
+		var statement attest.Statement
+		Expect(json.NewDecoder(rc).Decode(&statement)).To(Succeed())
+		Expect(statement.Type).To(Equal(attest.StatementType))
+	})
+})
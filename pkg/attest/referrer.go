@@ -0,0 +1,105 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package attest
+
+import (
+	"encoding/json"
+	"fmt"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+const (
+	// StatementMediaType is the media type of an in-toto statement
+	StatementMediaType types.MediaType = "application/vnd.in-toto+json"
+
+	// emptyMediaType is the media type of the empty config of artifacts
+	emptyMediaType types.MediaType = "application/vnd.oci.empty.v1+json"
+)
+
+// referrer is an OCI artifact with the statement as its only layer
+type referrer struct {
+	manifest []byte
+	layer    v1.Layer
+}
+
+// Referrer creates an OCI artifact with the statement, which refers to the
+// subject, so that it can be pushed next to the image and found using the
+// OCI referrers API
+func Referrer(statement Statement, subject v1.Descriptor) (v1.Image, error) {
+	data, err := json.Marshal(statement)
+	if err != nil {
+		return nil, err
+	}
+
+	var layer = static.NewLayer(data, StatementMediaType)
+	layerDesc, err := partial.Descriptor(layer)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := partial.Descriptor(static.NewLayer([]byte("{}"), emptyMediaType))
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := json.Marshal(v1.Manifest{
+		SchemaVersion: 2,
+		MediaType:     types.OCIManifestSchema1,
+		ArtifactType:  string(StatementMediaType),
+		Config:        *config,
+		Layers:        []v1.Descriptor{*layerDesc},
+		Subject:       &v1.Descriptor{MediaType: subject.MediaType, Size: subject.Size, Digest: subject.Digest},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return partial.CompressedToImage(&referrer{manifest: manifest, layer: layer})
+}
+
+func (r *referrer) RawConfigFile() ([]byte, error) {
+	return []byte("{}"), nil
+}
+
+func (r *referrer) MediaType() (types.MediaType, error) {
+	return types.OCIManifestSchema1, nil
+}
+
+func (r *referrer) RawManifest() ([]byte, error) {
+	return r.manifest, nil
+}
+
+func (r *referrer) LayerByDigest(hash v1.Hash) (partial.CompressedLayer, error) {
+	digest, err := r.layer.Digest()
+	if err != nil {
+		return nil, err
+	}
+
+	if hash != digest {
+		return nil, fmt.Errorf("artifact has no layer %s", hash)
+	}
+
+	return r.layer, nil
+}
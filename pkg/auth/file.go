@@ -0,0 +1,259 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package auth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+// File is a registry auth file in the Docker config.json format, which is
+// also the format of the Podman auth.json file
+type File struct {
+	Path string
+
+	Auths       map[string]Entry
+	CredHelpers map[string]string
+	CredsStore  string
+
+	// other settings in the file, kept as-is when saving
+	other map[string]json.RawMessage
+}
+
+// Entry is the credentials entry of a registry or repository
+type Entry struct {
+	Auth          string `json:"auth,omitempty"`
+	IdentityToken string `json:"identitytoken,omitempty"`
+	RegistryToken string `json:"registrytoken,omitempty"`
+}
+
+// DefaultFile returns the auth file used when none is specified, which is
+// the Podman auth file if REGISTRY_AUTH_FILE is set, otherwise the Docker
+// config file
+func DefaultFile() string {
+	if path, ok := os.LookupEnv("REGISTRY_AUTH_FILE"); ok && path != "" {
+		return path
+	}
+
+	if dir, ok := os.LookupEnv("DOCKER_CONFIG"); ok && dir != "" {
+		return filepath.Join(dir, "config.json")
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".docker", "config.json")
+	}
+
+	return filepath.Join(home, ".docker", "config.json")
+}
+
+// LoadFile reads the auth file, a file that does not exist yet results in
+// an empty auth file
+func LoadFile(path string) (*File, error) {
+	var file = File{
+		Path:        path,
+		Auths:       map[string]Entry{},
+		CredHelpers: map[string]string{},
+		other:       map[string]json.RawMessage{},
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &file, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &file.other); err != nil {
+		return nil, fmt.Errorf("failed to parse auth file %s: %w", path, err)
+	}
+
+	for key, target := range map[string]any{
+		"auths":       &file.Auths,
+		"credHelpers": &file.CredHelpers,
+		"credsStore":  &file.CredsStore,
+	} {
+		if raw, ok := file.other[key]; ok {
+			if err := json.Unmarshal(raw, target); err != nil {
+				return nil, fmt.Errorf("failed to parse %s in auth file %s: %w", key, path, err)
+			}
+
+			delete(file.other, key)
+		}
+	}
+
+	return &file, nil
+}
+
+// Save writes the auth file, the file is only readable by the user
+func (f *File) Save() error {
+	var out = map[string]any{}
+	for key, value := range f.other {
+		out[key] = value
+	}
+
+	out["auths"] = f.Auths
+	if len(f.CredHelpers) > 0 {
+		out["credHelpers"] = f.CredHelpers
+	}
+
+	if f.CredsStore != "" {
+		out["credsStore"] = f.CredsStore
+	}
+
+	data, err := json.MarshalIndent(out, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(f.Path, append(data, '\n'), 0600)
+}
+
+// Helper returns the credential helper configured for the registry, if any
+func (f *File) Helper(registry string) Helper {
+	for key, helper := range f.CredHelpers {
+		if normalize(key) == normalize(registry) {
+			return Helper(helper)
+		}
+	}
+
+	return Helper(f.CredsStore)
+}
+
+// Set stores the username and password for the registry in the file
+func (f *File) Set(registry string, username string, password string) {
+	f.Remove(registry)
+	f.Auths[serverAddress(registry)] = Entry{
+		Auth: base64.StdEncoding.EncodeToString([]byte(username + ":" + password)),
+	}
+}
+
+// Remove removes all entries of the registry from the file, including the
+// ones of repositories in that registry, and reports whether there were any
+func (f *File) Remove(registry string) bool {
+	var removed bool
+	for key := range f.Auths {
+		var host, _, _ = strings.Cut(normalize(key), "/")
+		if host == normalize(registry) {
+			delete(f.Auths, key)
+			removed = true
+		}
+	}
+
+	return removed
+}
+
+// Resolve implements authn.Keychain, entries of repositories take precedence
+// over the entry of the registry (Podman style), and a credential helper
+// configured for the registry takes precedence over any entry
+func (f *File) Resolve(target authn.Resource) (authn.Authenticator, error) {
+	if helper := f.Helper(target.RegistryStr()); helper != "" {
+		return helper.Resolve(target)
+	}
+
+	var entries = map[string]Entry{}
+	for key, entry := range f.Auths {
+		entries[normalize(key)] = entry
+	}
+
+	for key := normalize(target.String()); key != "." && key != ""; key = parent(key) {
+		entry, ok := entries[key]
+		if !ok {
+			continue
+		}
+
+		var cfg = authn.AuthConfig{
+			IdentityToken: entry.IdentityToken,
+			RegistryToken: entry.RegistryToken,
+		}
+
+		if entry.Auth != "" {
+			data, err := base64.StdEncoding.DecodeString(entry.Auth)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode credentials of %s in auth file %s: %w", key, f.Path, err)
+			}
+
+			var ok bool
+			cfg.Username, cfg.Password, ok = strings.Cut(string(data), ":")
+			if !ok {
+				return nil, fmt.Errorf("credentials of %s in auth file %s are not in the format username:password", key, f.Path)
+			}
+		}
+
+		return authn.FromConfig(cfg), nil
+	}
+
+	return authn.Anonymous, nil
+}
+
+// normalize strips the scheme and API version from auth file keys, that
+// Docker adds for some registries, and uses one name for Docker Hub
+func normalize(key string) string {
+	key = strings.TrimPrefix(key, "https://")
+	key = strings.TrimPrefix(key, "http://")
+	key = strings.TrimSuffix(key, "/")
+	key = strings.TrimSuffix(key, "/v1")
+	key = strings.TrimSuffix(key, "/v2")
+
+	switch host, rest, _ := strings.Cut(key, "/"); host {
+	case "docker.io", "registry-1.docker.io":
+		if rest != "" {
+			return name.DefaultRegistry + "/" + rest
+		}
+
+		return name.DefaultRegistry
+	}
+
+	return key
+}
+
+func parent(key string) string {
+	if idx := strings.LastIndex(key, "/"); idx >= 0 {
+		return key[:idx]
+	}
+
+	return ""
+}
+
+// serverAddress returns the name Docker uses for the registry in auth
+// files and credential helpers
+func serverAddress(registry string) string {
+	if normalize(registry) == name.DefaultRegistry {
+		return authn.DefaultAuthKey
+	}
+
+	return registry
+}
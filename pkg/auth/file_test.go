@@ -0,0 +1,106 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package auth_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/homeport/forklift/pkg/auth"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+
+	"github.com/homeport/forklift/internal/imagetest"
+)
+
+var _ = Describe("Auth file", func() {
+	var path string
+
+	BeforeEach(func() {
+		path = filepath.Join(GinkgoT().TempDir(), "config.json")
+	})
+
+	write := func(content string) {
+		GinkgoHelper()
+		Expect(os.WriteFile(path, []byte(content), 0600)).To(Succeed())
+	}
+
+	It("should start with an empty file if it does not exist", func() {
+		file, err := auth.LoadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(file.Auths).To(BeEmpty())
+		Expect(resolve(file, imagetest.Must(name.NewRegistry("ghcr.io")))).To(Equal(authn.AuthConfig{}))
+	})
+
+	It("should resolve Docker config entries, including Docker Hub", func() {
+		write(`{"auths": {
+			"https://index.docker.io/v1/": {"auth": "aHViOnNlY3JldA=="},
+			"ghcr.io": {"auth": "Zm9vOmJhcg=="}
+		}}`)
+
+		file := imagetest.Must(auth.LoadFile(path))
+		Expect(resolve(file, imagetest.Must(name.NewRepository("library/ubuntu")))).To(Equal(authn.AuthConfig{Username: "hub", Password: "secret"}))
+		Expect(resolve(file, imagetest.Must(name.NewRepository("ghcr.io/homeport/forklift")))).To(Equal(authn.AuthConfig{Username: "foo", Password: "bar"}))
+		Expect(resolve(file, imagetest.Must(name.NewRepository("quay.io/foo/bar")))).To(Equal(authn.AuthConfig{}))
+	})
+
+	It("should prefer Podman repository entries over the registry entry", func() {
+		write(`{"auths": {
+			"quay.io": {"auth": "Zm9vOmJhcg=="},
+			"quay.io/team": {"auth": "dGVhbTpzZWNyZXQ="},
+			"docker.io/library": {"auth": "aHViOnNlY3JldA=="}
+		}}`)
+
+		file := imagetest.Must(auth.LoadFile(path))
+		Expect(resolve(file, imagetest.Must(name.NewRepository("quay.io/team/app")))).To(Equal(authn.AuthConfig{Username: "team", Password: "secret"}))
+		Expect(resolve(file, imagetest.Must(name.NewRepository("quay.io/other/app")))).To(Equal(authn.AuthConfig{Username: "foo", Password: "bar"}))
+		Expect(resolve(file, imagetest.Must(name.NewRepository("ubuntu")))).To(Equal(authn.AuthConfig{Username: "hub", Password: "secret"}))
+	})
+
+	It("should keep other settings when saving", func() {
+		write(`{"auths": {"ghcr.io": {"auth": "Zm9vOmJhcg=="}}, "credHelpers": {"gcr.io": "gcloud"}, "detachKeys": "ctrl-e,e"}`)
+
+		file := imagetest.Must(auth.LoadFile(path))
+		Expect(file.Helper("gcr.io")).To(Equal(auth.Helper("gcloud")))
+
+		file.Set("index.docker.io", "hub", "secret")
+		Expect(file.Remove("ghcr.io")).To(BeTrue())
+		Expect(file.Remove("ghcr.io")).To(BeFalse())
+		Expect(file.Save()).To(Succeed())
+
+		var saved map[string]any
+		Expect(json.Unmarshal(imagetest.Must(os.ReadFile(path)), &saved)).To(Succeed())
+		Expect(saved).To(Equal(map[string]any{
+			"auths":       map[string]any{"https://index.docker.io/v1/": map[string]any{"auth": "aHViOnNlY3JldA=="}},
+			"credHelpers": map[string]any{"gcr.io": "gcloud"},
+			"detachKeys":  "ctrl-e,e",
+		}))
+
+		info, err := os.Stat(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+	})
+})
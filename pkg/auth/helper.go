@@ -0,0 +1,76 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package auth
+
+import (
+	"github.com/docker/docker-credential-helpers/client"
+	"github.com/docker/docker-credential-helpers/credentials"
+	"github.com/google/go-containerregistry/pkg/authn"
+)
+
+// identityTokenUsername is the username credential helpers use to signal
+// that the secret is an identity token
+const identityTokenUsername = "<token>"
+
+// Helper is the name of a Docker credential helper, for example "pass" for
+// the program docker-credential-pass
+type Helper string
+
+func (h Helper) program() client.ProgramFunc {
+	return client.NewShellProgramFunc("docker-credential-" + string(h))
+}
+
+// Resolve implements authn.Keychain
+func (h Helper) Resolve(target authn.Resource) (authn.Authenticator, error) {
+	creds, err := client.Get(h.program(), serverAddress(target.RegistryStr()))
+	if credentials.IsErrCredentialsNotFound(err) {
+		return authn.Anonymous, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if creds.Username == identityTokenUsername {
+		return authn.FromConfig(authn.AuthConfig{IdentityToken: creds.Secret}), nil
+	}
+
+	return authn.FromConfig(authn.AuthConfig{Username: creds.Username, Password: creds.Secret}), nil
+}
+
+// Store stores the username and password for the registry in the helper
+func (h Helper) Store(registry string, username string, password string) error {
+	return client.Store(h.program(), &credentials.Credentials{
+		ServerURL: serverAddress(registry),
+		Username:  username,
+		Secret:    password,
+	})
+}
+
+// Erase removes the credentials of the registry from the helper
+func (h Helper) Erase(registry string) error {
+	err := client.Erase(h.program(), serverAddress(registry))
+	if credentials.IsErrCredentialsNotFound(err) {
+		return nil
+	}
+
+	return err
+}
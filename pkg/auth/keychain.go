@@ -0,0 +1,117 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package auth
+
+import (
+	"github.com/google/go-containerregistry/pkg/authn"
+)
+
+// Options configures where registry credentials come from
+type Options struct {
+	// Username and Password are used for the registries in Registries
+	Username string
+	Password string
+
+	// RegistryToken is a bearer token used for the registries in Registries
+	RegistryToken string
+
+	// Registries are the registries the explicit credentials are sent to,
+	// without registries the explicit credentials are not used at all
+	Registries []string
+
+	// AuthFile is the Docker or Podman auth file to use instead of the
+	// default locations
+	AuthFile string
+
+	// Helpers selects the credential helper per registry
+	Helpers map[string]Helper
+}
+
+// Keychain returns a keychain that resolves credentials in this order:
+// explicit username and password or registry token, credential helper
+// selected for the registry, the given auth file, and finally the default
+// Docker and Podman auth file locations
+func Keychain(o Options) (authn.Keychain, error) {
+	var keychains []authn.Keychain
+	switch {
+	case o.RegistryToken != "":
+		keychains = append(keychains, static{authn.FromConfig(authn.AuthConfig{RegistryToken: o.RegistryToken}), o.Registries})
+
+	case o.Username != "" || o.Password != "":
+		keychains = append(keychains, static{authn.FromConfig(authn.AuthConfig{Username: o.Username, Password: o.Password}), o.Registries})
+	}
+
+	if len(o.Helpers) > 0 {
+		keychains = append(keychains, helpers(o))
+	}
+
+	if o.AuthFile != "" {
+		file, err := LoadFile(o.AuthFile)
+		if err != nil {
+			return nil, err
+		}
+
+		keychains = append(keychains, file)
+
+	} else {
+		keychains = append(keychains, authn.DefaultKeychain)
+	}
+
+	return authn.NewMultiKeychain(keychains...), nil
+}
+
+// Helper returns the credential helper selected for the registry, if any
+func (o Options) Helper(registry string) Helper {
+	for key, helper := range o.Helpers {
+		if normalize(key) == normalize(registry) {
+			return helper
+		}
+	}
+
+	return ""
+}
+
+// static uses the same authenticator for the given registries
+type static struct {
+	authn.Authenticator
+	registries []string
+}
+
+func (s static) Resolve(target authn.Resource) (authn.Authenticator, error) {
+	for _, registry := range s.registries {
+		if normalize(registry) == normalize(target.RegistryStr()) {
+			return s.Authenticator, nil
+		}
+	}
+
+	return authn.Anonymous, nil
+}
+
+// helpers uses the credential helper selected for the registry
+type helpers Options
+
+func (h helpers) Resolve(target authn.Resource) (authn.Authenticator, error) {
+	if helper := Options(h).Helper(target.RegistryStr()); helper != "" {
+		return helper.Resolve(target)
+	}
+
+	return authn.Anonymous, nil
+}
@@ -0,0 +1,105 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package auth_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/homeport/forklift/pkg/auth"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+
+	"github.com/homeport/forklift/internal/imagetest"
+)
+
+var _ = Describe("Keychain", func() {
+	var authFile string
+
+	BeforeEach(func() {
+		authFile = filepath.Join(GinkgoT().TempDir(), "auth.json")
+		Expect(os.WriteFile(authFile, []byte(`{"auths": {"ghcr.io": {"auth": "Zm9vOmJhcg=="}}}`), 0600)).To(Succeed())
+	})
+
+	It("should use the given auth file", func() {
+		keychain := imagetest.Must(auth.Keychain(auth.Options{AuthFile: authFile}))
+		Expect(resolve(keychain, imagetest.Must(name.NewRepository("ghcr.io/foo/bar")))).To(Equal(authn.AuthConfig{Username: "foo", Password: "bar"}))
+	})
+
+	It("should prefer explicit credentials over the auth file", func() {
+		keychain := imagetest.Must(auth.Keychain(auth.Options{Username: "user", Password: "pass", Registries: []string{"ghcr.io"}, AuthFile: authFile}))
+		Expect(resolve(keychain, imagetest.Must(name.NewRepository("ghcr.io/foo/bar")))).To(Equal(authn.AuthConfig{Username: "user", Password: "pass"}))
+
+		keychain = imagetest.Must(auth.Keychain(auth.Options{RegistryToken: "token", Registries: []string{"quay.io"}, AuthFile: authFile}))
+		Expect(resolve(keychain, imagetest.Must(name.NewRepository("quay.io/foo/bar")))).To(Equal(authn.AuthConfig{RegistryToken: "token"}))
+	})
+
+	It("should send explicit credentials only to their registries", func() {
+		keychain := imagetest.Must(auth.Keychain(auth.Options{Username: "user", Password: "pass", Registries: []string{"docker.io"}, AuthFile: authFile}))
+		Expect(resolve(keychain, imagetest.Must(name.NewRepository("library/alpine")))).To(Equal(authn.AuthConfig{Username: "user", Password: "pass"}))
+		Expect(resolve(keychain, imagetest.Must(name.NewRepository("ghcr.io/foo/bar")))).To(Equal(authn.AuthConfig{Username: "foo", Password: "bar"}))
+		Expect(resolve(keychain, imagetest.Must(name.NewRepository("quay.io/foo/bar")))).To(Equal(authn.AuthConfig{}))
+	})
+
+	It("should select credential helpers per registry", func() {
+		var options = auth.Options{Helpers: map[string]auth.Helper{"docker.io": "pass"}}
+		Expect(options.Helper("index.docker.io")).To(Equal(auth.Helper("pass")))
+		Expect(options.Helper("ghcr.io")).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Verify", func() {
+	var host string
+
+	BeforeEach(func() {
+		var handler = registry.New()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if username, password, ok := r.BasicAuth(); !ok || username != "foo" || password != "bar" {
+				w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			handler.ServeHTTP(w, r)
+		}))
+
+		DeferCleanup(server.Close)
+		host = strings.TrimPrefix(server.URL, "http://")
+	})
+
+	It("should accept valid credentials", func() {
+		Expect(auth.Verify(context.Background(), imagetest.Must(name.NewRegistry(host)), authn.FromConfig(authn.AuthConfig{Username: "foo", Password: "bar"}), remote.DefaultTransport)).To(Succeed())
+	})
+
+	It("should reject invalid credentials", func() {
+		Expect(auth.Verify(context.Background(), imagetest.Must(name.NewRegistry(host)), authn.FromConfig(authn.AuthConfig{Username: "foo", Password: "baz"}), remote.DefaultTransport)).To(MatchError(ContainSubstring("rejected the credentials")))
+	})
+})
@@ -0,0 +1,67 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package auth_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+
+	"github.com/homeport/forklift/pkg/auth"
+)
+
+var _ = Describe("Verify", func() {
+	// registry serves plain HTTP, a basic auth challenge is sent to requests
+	// without credentials, others are answered with the status
+	var registry = func(status int) name.Registry {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, _, ok := r.BasicAuth(); !ok {
+				w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			w.WriteHeader(status)
+		}))
+		DeferCleanup(server.Close)
+
+		registry, err := name.NewRegistry(strings.TrimPrefix(server.URL, "http://"))
+		Expect(err).ToNot(HaveOccurred())
+		return registry
+	}
+
+	var credentials = authn.FromConfig(authn.AuthConfig{Username: "user", Password: "secret"})
+
+	It("should use the scheme of the registry", func() {
+		Expect(auth.Verify(context.Background(), registry(http.StatusOK), credentials, http.DefaultTransport)).To(Succeed())
+	})
+
+	It("should fail if the registry rejects the credentials", func() {
+		Expect(auth.Verify(context.Background(), registry(http.StatusForbidden), credentials, http.DefaultTransport)).To(MatchError(ContainSubstring("rejected the credentials")))
+	})
+})
@@ -0,0 +1,334 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// Cache is an on-disk content-addressable store for blobs (layers, configs,
+// and manifests), which are stored in blobs/<algorithm>/<hex> of the cache
+// directory
+type Cache struct {
+	Dir string
+
+	// MaxSize is the size in bytes the cache is kept under by removing the
+	// least recently used blobs, zero means no limit
+	MaxSize int64
+}
+
+// Entry is a blob in the cache
+type Entry struct {
+	Digest   v1.Hash   `json:"digest"`
+	Size     int64     `json:"size"`
+	LastUsed time.Time `json:"lastUsed"`
+}
+
+// DefaultDir returns the cache directory in the user's cache directory
+func DefaultDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "forklift")
+	}
+
+	return filepath.Join(dir, "forklift")
+}
+
+// Entries lists all blobs in the cache, least recently used first
+func (c *Cache) Entries() ([]Entry, error) {
+	var entries []Entry
+	err := filepath.WalkDir(filepath.Join(c.Dir, "blobs"), func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		digest, err := v1.NewHash(filepath.Base(filepath.Dir(path)) + ":" + d.Name())
+		if err != nil {
+			// not a blob of the cache
+			return nil
+		}
+
+		entries = append(entries, Entry{Digest: digest, Size: info.Size(), LastUsed: info.ModTime()})
+		return nil
+	})
+
+	slices.SortFunc(entries, func(a, b Entry) int { return a.LastUsed.Compare(b.LastUsed) })
+	return entries, err
+}
+
+// Size returns the total size of all blobs in the cache
+func (c *Cache) Size() (int64, error) {
+	entries, err := c.Entries()
+	if err != nil {
+		return 0, err
+	}
+
+	var size int64
+	for _, entry := range entries {
+		size += entry.Size
+	}
+
+	return size, nil
+}
+
+// Prune removes blobs that were not used since the given time, and
+// incomplete downloads, it returns the removed entries
+func (c *Cache) Prune(before time.Time) ([]Entry, error) {
+	entries, err := c.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []Entry
+	for _, entry := range entries {
+		if entry.LastUsed.Before(before) {
+			if err := c.Delete(entry.Digest); err != nil {
+				return removed, err
+			}
+
+			removed = append(removed, entry)
+		}
+	}
+
+	return removed, c.removeTemporaryFiles(before)
+}
+
+// Evict removes the least recently used blobs until the cache is not larger
+// than the given size, it returns the removed entries
+func (c *Cache) Evict(size int64) ([]Entry, error) {
+	entries, err := c.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	for _, entry := range entries {
+		total += entry.Size
+	}
+
+	var removed []Entry
+	for _, entry := range entries {
+		if total <= size {
+			break
+		}
+
+		if err := c.Delete(entry.Digest); err != nil {
+			return removed, err
+		}
+
+		total -= entry.Size
+		removed = append(removed, entry)
+	}
+
+	return removed, nil
+}
+
+// Clear removes all blobs and incomplete downloads, but keeps the cache
+// directory and anything else in it
+func (c *Cache) Clear() error {
+	return errors.Join(
+		os.RemoveAll(filepath.Join(c.Dir, "blobs")),
+		os.RemoveAll(filepath.Join(c.Dir, "tmp")),
+	)
+}
+
+// Delete removes the blob from the cache
+func (c *Cache) Delete(digest v1.Hash) error {
+	err := os.Remove(c.path(digest))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+// open returns the blob from the cache, or from fetch, in which case the
+// blob is stored in the cache once it was read completely and its digest
+// was verified
+func (c *Cache) open(digest v1.Hash, fetch func() (io.ReadCloser, error)) (io.ReadCloser, error) {
+	var path = c.path(digest)
+	if file, err := os.Open(path); err == nil {
+		var now = time.Now()
+		_ = os.Chtimes(path, now, now)
+		return file, nil
+	}
+
+	rc, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Join(c.Dir, "tmp"), 0755); err != nil {
+		return nil, errors.Join(err, rc.Close())
+	}
+
+	tmp, err := os.CreateTemp(filepath.Join(c.Dir, "tmp"), digest.Hex)
+	if err != nil {
+		return nil, errors.Join(err, rc.Close())
+	}
+
+	hasher, err := newHash(digest)
+	if err != nil {
+		return nil, errors.Join(err, rc.Close(), tmp.Close(), os.Remove(tmp.Name()))
+	}
+
+	return &storingReader{
+		Reader: io.TeeReader(rc, io.MultiWriter(tmp, hasher)),
+		cache:  c,
+		digest: digest,
+		hash:   hasher,
+		source: rc,
+		tmp:    tmp,
+	}, nil
+}
+
+// read returns the blob from the cache, or from fetch, see open
+func (c *Cache) read(digest v1.Hash, fetch func() (io.ReadCloser, error)) ([]byte, error) {
+	rc, err := c.open(digest, fetch)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(rc)
+	return data, errors.Join(err, rc.Close())
+}
+
+// lookup returns the blob if it is in the cache
+func (c *Cache) lookup(digest v1.Hash) ([]byte, bool) {
+	data, err := c.read(digest, func() (io.ReadCloser, error) { return nil, fs.ErrNotExist })
+	return data, err == nil
+}
+
+// store stores the blob in the cache
+func (c *Cache) store(digest v1.Hash, data []byte) error {
+	_, err := c.read(digest, func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(data)), nil })
+	return err
+}
+
+func (c *Cache) path(digest v1.Hash) string {
+	return filepath.Join(c.Dir, "blobs", digest.Algorithm, digest.Hex)
+}
+
+// commit moves the downloaded blob into place and evicts blobs if the
+// cache got too large
+func (c *Cache) commit(tmp string, digest v1.Hash) error {
+	if err := os.MkdirAll(filepath.Dir(c.path(digest)), 0755); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp, c.path(digest)); err != nil {
+		return err
+	}
+
+	if c.MaxSize > 0 {
+		_, err := c.Evict(c.MaxSize)
+		return err
+	}
+
+	return nil
+}
+
+func (c *Cache) removeTemporaryFiles(before time.Time) error {
+	files, err := os.ReadDir(filepath.Join(c.Dir, "tmp"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if info, err := file.Info(); err == nil && info.ModTime().Before(before) {
+			if err := os.RemoveAll(filepath.Join(c.Dir, "tmp", file.Name())); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func newHash(digest v1.Hash) (hash.Hash, error) {
+	switch digest.Algorithm {
+	case "sha256":
+		return sha256.New(), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported digest algorithm %s", digest.Algorithm)
+	}
+}
+
+// storingReader stores everything it reads in a temporary file, which is
+// moved into the cache when the blob was read completely
+type storingReader struct {
+	io.Reader
+
+	cache  *Cache
+	digest v1.Hash
+	hash   hash.Hash
+	source io.ReadCloser
+	tmp    *os.File
+	eof    bool
+}
+
+func (r *storingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if err == io.EOF {
+		r.eof = true
+	}
+
+	return n, err
+}
+
+func (r *storingReader) Close() error {
+	var err = errors.Join(r.source.Close(), r.tmp.Close())
+	if err == nil && r.eof && fmt.Sprintf("%x", r.hash.Sum(nil)) == r.digest.Hex {
+		if err := r.cache.commit(r.tmp.Name(), r.digest); err == nil {
+			return nil
+		}
+	}
+
+	// incomplete or broken downloads are not stored
+	_ = os.Remove(r.tmp.Name())
+	return err
+}
@@ -0,0 +1,196 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cache_test
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/homeport/forklift/pkg/cache"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+
+	"github.com/homeport/forklift/internal/imagetest"
+)
+
+var _ = Describe("Cache", func() {
+	var (
+		blobRequests atomic.Int64
+		mounts       atomic.Int64
+		server       *httptest.Server
+		blobs        *cache.Cache
+		ref          name.Reference
+		sample       v1.Image
+	)
+
+	BeforeEach(func() {
+		blobRequests.Store(0)
+
+		var handler = registry.New(registry.Logger(log.New(io.Discard, "", 0)))
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/blobs/") {
+				blobRequests.Add(1)
+			}
+
+			// the test registry shares blobs between repositories, so that
+			// blobs of the copy would always exist without this
+			if r.Method == http.MethodHead && strings.HasPrefix(r.URL.Path, "/v2/test/copy/blobs/") {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			if r.Method == http.MethodPost && r.URL.Query().Has("mount") {
+				mounts.Add(1)
+			}
+
+			handler.ServeHTTP(w, r)
+		}))
+		DeferCleanup(server.Close)
+
+		sample = imagetest.Must(random.Image(1024, 2))
+		ref = imagetest.Must(name.ParseReference(strings.TrimPrefix(server.URL, "http://") + "/test/cache:latest"))
+		Expect(remote.Write(ref, sample)).To(Succeed())
+
+		blobs = &cache.Cache{Dir: GinkgoT().TempDir()}
+	})
+
+	readBlobs := func(image v1.Image) {
+		GinkgoHelper()
+
+		_ = imagetest.Must(image.ConfigFile())
+		for _, layer := range imagetest.Must(image.Layers()) {
+			rc := imagetest.Must(layer.Compressed())
+			_ = imagetest.Must(io.Copy(io.Discard, rc))
+			Expect(rc.Close()).To(Succeed())
+		}
+	}
+
+	Context("loading images", func() {
+		It("should serve blobs read before from the cache", func() {
+			image, _ := must2(blobs.Remote(ref))
+			Expect(imagetest.Must(image.Digest())).To(Equal(imagetest.Must(sample.Digest())))
+			readBlobs(image)
+
+			var requests = blobRequests.Load()
+			Expect(requests).To(BeNumerically(">", 0))
+
+			// manifest, config, and two layers
+			Expect(imagetest.Must(blobs.Entries())).To(HaveLen(4))
+
+			image, _ = must2(blobs.Remote(ref))
+			Expect(imagetest.Must(image.ConfigFile())).To(Equal(imagetest.Must(sample.ConfigFile())))
+			readBlobs(image)
+			Expect(blobRequests.Load()).To(Equal(requests))
+		})
+
+		It("should not store blobs that were not read completely", func() {
+			image, _ := must2(blobs.Remote(ref))
+
+			layer := imagetest.Must(image.Layers())[0]
+			rc := imagetest.Must(layer.Compressed())
+			_ = imagetest.Must(io.CopyN(io.Discard, rc, 16))
+			Expect(rc.Close()).To(Succeed())
+
+			var digest = imagetest.Must(layer.Digest())
+			for _, entry := range imagetest.Must(blobs.Entries()) {
+				Expect(entry.Digest).ToNot(Equal(digest))
+			}
+		})
+
+		It("should keep the cache below the maximum size", func() {
+			blobs.MaxSize = 2048
+
+			image, _ := must2(blobs.Remote(ref))
+			readBlobs(image)
+
+			Expect(imagetest.Must(blobs.Size())).To(BeNumerically("<=", 2048))
+		})
+	})
+
+	Context("removing blobs", func() {
+		BeforeEach(func() {
+			image, _ := must2(blobs.Remote(ref))
+			readBlobs(image)
+		})
+
+		It("should prune blobs not used since the given time", func() {
+			Expect(imagetest.Must(blobs.Prune(time.Now().Add(-time.Hour)))).To(BeEmpty())
+
+			removed := imagetest.Must(blobs.Prune(time.Now().Add(time.Second)))
+			Expect(removed).To(HaveLen(4))
+			Expect(imagetest.Must(blobs.Entries())).To(BeEmpty())
+		})
+
+		It("should evict least recently used blobs first", func() {
+			entries := imagetest.Must(blobs.Entries())
+			var last = entries[len(entries)-1]
+
+			removed := imagetest.Must(blobs.Evict(last.Size))
+			Expect(removed).To(HaveLen(len(entries) - 1))
+			Expect(imagetest.Must(blobs.Entries())).To(ConsistOf(last))
+		})
+
+		It("should remove all blobs when cleared", func() {
+			var other = filepath.Join(blobs.Dir, "other")
+			Expect(os.WriteFile(other, []byte("keep"), 0600)).To(Succeed())
+
+			Expect(blobs.Clear()).To(Succeed())
+			Expect(imagetest.Must(blobs.Size())).To(BeZero())
+			Expect(filepath.Join(blobs.Dir, "blobs")).ToNot(BeADirectory())
+			Expect(other).To(BeARegularFile())
+		})
+	})
+
+	Context("writing images", func() {
+		It("should mount blobs from the source repository of the same registry", func() {
+			mounts.Store(0)
+
+			image, _ := must2(blobs.Remote(ref))
+			target := imagetest.Must(name.ParseReference(ref.Context().RegistryStr() + "/test/copy:latest"))
+			Expect(remote.Write(target, image)).To(Succeed())
+
+			// config and two layers
+			Expect(mounts.Load()).To(Equal(int64(3)))
+			Expect(imagetest.Must(remote.Image(target)).Digest()).To(Equal(imagetest.Must(sample.Digest())))
+		})
+	})
+})
+
+func must2[T, U any](t T, u U, err error) (T, U) {
+	GinkgoHelper()
+
+	Expect(err).ToNot(HaveOccurred())
+	return t, u
+}
@@ -0,0 +1,289 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// Remote loads the image or image index of the reference from the registry,
+// manifests, configs, and layers are read from the cache if possible, and
+// stored in the cache when they are read from the registry. A tag is
+// resolved with a HEAD request, which does not count towards the Docker Hub
+// rate limit, so only manifests not in the cache yet are downloaded.
+func (c *Cache) Remote(ref name.Reference, opts ...remote.Option) (v1.Image, v1.ImageIndex, error) {
+	manifest, mediaType, err := c.manifest(ref, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if mediaType.IsIndex() {
+		return nil, &index{cache: c, repo: ref.Context(), opts: opts, manifest: manifest, mediaType: mediaType}, nil
+	}
+
+	image, err := c.image(ref.Context(), opts, manifest, mediaType)
+	return image, nil, err
+}
+
+// manifest returns the manifest of the reference and its media type
+func (c *Cache) manifest(ref name.Reference, opts []remote.Option) ([]byte, types.MediaType, error) {
+	var digest v1.Hash
+	switch ref := ref.(type) {
+	case name.Digest:
+		h, err := v1.NewHash(ref.DigestStr())
+		if err != nil {
+			return nil, "", err
+		}
+
+		digest = h
+
+	default:
+		if desc, err := remote.Head(ref, opts...); err == nil {
+			digest = desc.Digest
+		}
+	}
+
+	if digest != (v1.Hash{}) {
+		if data, ok := c.lookup(digest); ok {
+			return data, mediaTypeOf(data), nil
+		}
+	}
+
+	desc, err := remote.Get(ref, opts...)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := c.store(desc.Digest, desc.Manifest); err != nil {
+		return nil, "", err
+	}
+
+	return desc.Manifest, desc.MediaType, nil
+}
+
+func (c *Cache) image(repo name.Repository, opts []remote.Option, manifest []byte, mediaType types.MediaType) (v1.Image, error) {
+	var core = &image{cache: c, repo: repo, opts: opts, manifest: manifest, mediaType: mediaType}
+	image, err := partial.CompressedToImage(core)
+	if err != nil {
+		return nil, err
+	}
+
+	core.extended = image
+	return &mountable{Image: image, repo: repo}, nil
+}
+
+// mountable wraps the layers of the image in remote.MountableLayer, so that
+// writing the image to another repository of the same registry mounts the
+// blobs instead of uploading them
+type mountable struct {
+	v1.Image
+	repo name.Repository
+}
+
+func (m *mountable) Layers() ([]v1.Layer, error) {
+	layers, err := m.Image.Layers()
+	if err != nil {
+		return nil, err
+	}
+
+	var result = make([]v1.Layer, 0, len(layers))
+	for _, layer := range layers {
+		mountableLayer, err := m.mount(layer, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, mountableLayer)
+	}
+
+	return result, nil
+}
+
+func (m *mountable) LayerByDigest(digest v1.Hash) (v1.Layer, error) {
+	return m.mount(m.Image.LayerByDigest(digest))
+}
+
+func (m *mountable) LayerByDiffID(diffID v1.Hash) (v1.Layer, error) {
+	return m.mount(m.Image.LayerByDiffID(diffID))
+}
+
+func (m *mountable) ConfigLayer() (v1.Layer, error) {
+	return m.mount(partial.ConfigLayer(m.Image))
+}
+
+func (m *mountable) mount(layer v1.Layer, err error) (v1.Layer, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	digest, err := layer.Digest()
+	if err != nil {
+		return nil, err
+	}
+
+	return &remote.MountableLayer{Layer: layer, Reference: m.repo.Digest(digest.String())}, nil
+}
+
+// image is an image of a registry, which reads its blobs through the cache
+type image struct {
+	cache     *Cache
+	repo      name.Repository
+	opts      []remote.Option
+	manifest  []byte
+	mediaType types.MediaType
+	extended  v1.Image
+}
+
+var _ partial.CompressedImageCore = (*image)(nil)
+
+func (i *image) RawManifest() ([]byte, error)        { return i.manifest, nil }
+func (i *image) MediaType() (types.MediaType, error) { return i.mediaType, nil }
+
+func (i *image) RawConfigFile() ([]byte, error) {
+	manifest, err := partial.Manifest(i)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.cache.read(manifest.Config.Digest, i.blob(manifest.Config.Digest))
+}
+
+func (i *image) LayerByDigest(digest v1.Hash) (partial.CompressedLayer, error) {
+	manifest, err := partial.Manifest(i)
+	if err != nil {
+		return nil, err
+	}
+
+	if manifest.Config.Digest == digest {
+		return &layer{image: i, desc: manifest.Config}, nil
+	}
+
+	for _, desc := range manifest.Layers {
+		if desc.Digest == digest {
+			return &layer{image: i, desc: desc}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("image has no blob %s", digest)
+}
+
+// blob returns a function that downloads the blob from the registry
+func (i *image) blob(digest v1.Hash) func() (io.ReadCloser, error) {
+	return func() (io.ReadCloser, error) {
+		layer, err := remote.Layer(i.repo.Digest(digest.String()), i.opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		return layer.Compressed()
+	}
+}
+
+// layer is a blob of an image, which is read through the cache
+type layer struct {
+	image *image
+	desc  v1.Descriptor
+}
+
+var _ partial.CompressedLayer = (*layer)(nil)
+
+func (l *layer) Digest() (v1.Hash, error)            { return l.desc.Digest, nil }
+func (l *layer) Size() (int64, error)                { return l.desc.Size, nil }
+func (l *layer) MediaType() (types.MediaType, error) { return l.desc.MediaType, nil }
+
+func (l *layer) DiffID() (v1.Hash, error) {
+	return partial.BlobToDiffID(l.image.extended, l.desc.Digest)
+}
+
+func (l *layer) Compressed() (io.ReadCloser, error) {
+	return l.image.cache.open(l.desc.Digest, l.image.blob(l.desc.Digest))
+}
+
+// index is an image index of a registry, which reads its manifests through
+// the cache
+type index struct {
+	cache     *Cache
+	repo      name.Repository
+	opts      []remote.Option
+	manifest  []byte
+	mediaType types.MediaType
+}
+
+var _ v1.ImageIndex = (*index)(nil)
+
+func (i *index) MediaType() (types.MediaType, error) { return i.mediaType, nil }
+func (i *index) RawManifest() ([]byte, error)        { return i.manifest, nil }
+func (i *index) Digest() (v1.Hash, error)            { return partial.Digest(i) }
+func (i *index) Size() (int64, error)                { return partial.Size(i) }
+
+func (i *index) IndexManifest() (*v1.IndexManifest, error) {
+	return v1.ParseIndexManifest(bytes.NewReader(i.manifest))
+}
+
+func (i *index) Image(digest v1.Hash) (v1.Image, error) {
+	manifest, mediaType, err := i.cache.manifest(i.repo.Digest(digest.String()), i.opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.cache.image(i.repo, i.opts, manifest, mediaType)
+}
+
+func (i *index) ImageIndex(digest v1.Hash) (v1.ImageIndex, error) {
+	manifest, mediaType, err := i.cache.manifest(i.repo.Digest(digest.String()), i.opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &index{cache: i.cache, repo: i.repo, opts: i.opts, manifest: manifest, mediaType: mediaType}, nil
+}
+
+// mediaTypeOf returns the media type of the manifest, OCI manifests do not
+// have to state it
+func mediaTypeOf(manifest []byte) types.MediaType {
+	var probe struct {
+		MediaType types.MediaType `json:"mediaType"`
+		Manifests json.RawMessage `json:"manifests"`
+	}
+
+	switch {
+	case json.Unmarshal(manifest, &probe) != nil:
+		return ""
+
+	case probe.MediaType != "":
+		return probe.MediaType
+
+	case probe.Manifests != nil:
+		return types.OCIImageIndex
+
+	default:
+		return types.OCIManifestSchema1
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+)
+
+// Image creates a new image based on the input image using the given config,
+// and appends a history entry without a layer that documents the change,
+// unless the input image has no history, where a single entry would break
+// the correspondence of history entries and layers
+func Image(input v1.Image, config v1.Config) (v1.Image, error) {
+	configFile, err := input.ConfigFile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image config file: %w", err)
+	}
+
+	changes := Describe(configFile.Config, config)
+	if len(changes) == 0 {
+		return input, nil
+	}
+
+	configFile = configFile.DeepCopy()
+	configFile.Config = config
+	if len(configFile.History) == 0 {
+		return mutate.ConfigFile(input, configFile)
+	}
+
+	configFile.History = append(configFile.History, v1.History{
+		Author:     "forklift",
+		Comment:    "updated image config",
+		Created:    v1.Time{Time: time.Now().UTC()},
+		CreatedBy:  strings.Join(changes, ", "),
+		EmptyLayer: true,
+	})
+
+	return mutate.ConfigFile(input, configFile)
+}
+
+// Describe lists the differences between the two configs in a Dockerfile
+// instruction like style, i.e. ENV KEY=value, or unset ENV KEY
+func Describe(before, after v1.Config) []string {
+	var result []string
+
+	var beforeEnv, afterEnv = envMap(before.Env), envMap(after.Env)
+	for _, key := range envKeys(after.Env) {
+		if value, ok := beforeEnv[key]; !ok || value != afterEnv[key] {
+			result = append(result, fmt.Sprintf("ENV %s=%s", key, afterEnv[key]))
+		}
+	}
+
+	for _, key := range envKeys(before.Env) {
+		if _, ok := afterEnv[key]; !ok {
+			result = append(result, "unset ENV "+key)
+		}
+	}
+
+	result = append(result, describeMap("LABEL", before.Labels, after.Labels)...)
+	result = append(result, describeMap("EXPOSE", before.ExposedPorts, after.ExposedPorts)...)
+	result = append(result, describeMap("VOLUME", before.Volumes, after.Volumes)...)
+
+	result = append(result, describeValue("ENTRYPOINT", before.Entrypoint, after.Entrypoint)...)
+	result = append(result, describeValue("CMD", before.Cmd, after.Cmd)...)
+	result = append(result, describeValue("SHELL", before.Shell, after.Shell)...)
+	result = append(result, describeValue("HEALTHCHECK", before.Healthcheck, after.Healthcheck)...)
+	result = append(result, describeValue("USER", before.User, after.User)...)
+	result = append(result, describeValue("WORKDIR", before.WorkingDir, after.WorkingDir)...)
+	result = append(result, describeValue("STOPSIGNAL", before.StopSignal, after.StopSignal)...)
+
+	if len(result) == 0 && !reflect.DeepEqual(before, after) {
+		result = append(result, "update config")
+	}
+
+	return result
+}
+
+// SetEnv sets the environment variable, keeping the position of an existing
+// entry with the same key
+func SetEnv(config *v1.Config, key, value string) {
+	for i, entry := range config.Env {
+		if k, _, _ := strings.Cut(entry, "="); k == key {
+			config.Env[i] = key + "=" + value
+			return
+		}
+	}
+
+	config.Env = append(config.Env, key+"="+value)
+}
+
+// UnsetEnv removes the environment variable
+func UnsetEnv(config *v1.Config, key string) {
+	config.Env = slices.DeleteFunc(config.Env, func(entry string) bool {
+		k, _, _ := strings.Cut(entry, "=")
+		return k == key
+	})
+}
+
+// envKeys returns the keys of the KEY=value environment entries
+func envKeys(env []string) []string {
+	var keys = make([]string, 0, len(env))
+	for _, entry := range env {
+		key, _, _ := strings.Cut(entry, "=")
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// envMap returns the KEY=value environment entries as a map
+func envMap(env []string) map[string]string {
+	var result = make(map[string]string, len(env))
+	for _, entry := range env {
+		key, value, _ := strings.Cut(entry, "=")
+		result[key] = value
+	}
+
+	return result
+}
+
+// describeMap describes added, changed, and removed map entries, for
+// example LABEL key=value, or unset LABEL key
+func describeMap[V any](instruction string, before, after map[string]V) []string {
+	var result []string
+	for _, key := range sortedKeys(after) {
+		if value, ok := before[key]; !ok || !reflect.DeepEqual(value, after[key]) {
+			if text, ok := any(after[key]).(string); ok {
+				result = append(result, fmt.Sprintf("%s %s=%s", instruction, key, text))
+			} else {
+				result = append(result, fmt.Sprintf("%s %s", instruction, key))
+			}
+		}
+	}
+
+	for _, key := range sortedKeys(before) {
+		if _, ok := after[key]; !ok {
+			result = append(result, fmt.Sprintf("unset %s %s", instruction, key))
+		}
+	}
+
+	return result
+}
+
+// describeValue describes a changed single value, for example WORKDIR /app,
+// or unset WORKDIR
+func describeValue[V any](instruction string, before, after V) []string {
+	if reflect.DeepEqual(before, after) {
+		return nil
+	}
+
+	if reflect.ValueOf(after).IsZero() {
+		return []string{"unset " + instruction}
+	}
+
+	if text, ok := any(after).(string); ok {
+		return []string{instruction + " " + text}
+	}
+
+	data, err := json.Marshal(after)
+	if err != nil {
+		return []string{instruction}
+	}
+
+	return []string{instruction + " " + string(data)}
+}
+
+// sortedKeys returns the map keys in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	var keys = make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+	return keys
+}
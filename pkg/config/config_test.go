@@ -0,0 +1,121 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/homeport/forklift/pkg/config"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+)
+
+var _ = Describe("Config", func() {
+	var before v1.Config
+
+	BeforeEach(func() {
+		before = v1.Config{
+			Env:        []string{"PATH=/bin", "DEBUG=true"},
+			Entrypoint: []string{"/app"},
+			Labels:     map[string]string{"vendor": "homeport"},
+		}
+	})
+
+	It("should describe changes in a Dockerfile like style", func() {
+		var after = *before.DeepCopy()
+		config.SetEnv(&after, "PATH", "/usr/bin:/bin")
+		config.UnsetEnv(&after, "DEBUG")
+		after.Labels["version"] = "1.0"
+		after.Entrypoint = nil
+		after.User = "nobody"
+
+		Expect(config.Describe(before, after)).To(Equal([]string{
+			"ENV PATH=/usr/bin:/bin",
+			"unset ENV DEBUG",
+			"LABEL version=1.0",
+			"unset ENTRYPOINT",
+			"USER nobody",
+		}))
+	})
+
+	It("should round-trip the config through YAML", func() {
+		data, err := config.ToYAML(before)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring("Entrypoint:\n  - /app\n"))
+
+		after, err := config.FromYAML(data)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(config.Describe(before, after)).To(BeEmpty())
+	})
+
+	It("should append an empty layer history entry for the change", func() {
+		image, err := random.Image(512, 2)
+		Expect(err).ToNot(HaveOccurred())
+
+		configFile, err := image.ConfigFile()
+		Expect(err).ToNot(HaveOccurred())
+
+		var cfg = *configFile.Config.DeepCopy()
+		config.SetEnv(&cfg, "FOO", "bar")
+
+		result, err := config.Image(image, cfg)
+		Expect(err).ToNot(HaveOccurred())
+
+		resultConfigFile, err := result.ConfigFile()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resultConfigFile.Config.Env).To(ContainElement("FOO=bar"))
+		Expect(resultConfigFile.History).To(HaveLen(len(configFile.History) + 1))
+
+		last := resultConfigFile.History[len(resultConfigFile.History)-1]
+		Expect(last.EmptyLayer).To(BeTrue())
+		Expect(last.CreatedBy).To(Equal("ENV FOO=bar"))
+
+		layers, err := result.Layers()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(layers).To(HaveLen(2))
+	})
+
+	It("should leave the history alone for images without history", func() {
+		image, err := random.Image(512, 2)
+		Expect(err).ToNot(HaveOccurred())
+
+		configFile, err := image.ConfigFile()
+		Expect(err).ToNot(HaveOccurred())
+
+		configFile.History = nil
+		image, err = mutate.ConfigFile(image, configFile)
+		Expect(err).ToNot(HaveOccurred())
+
+		var cfg = *configFile.Config.DeepCopy()
+		config.SetEnv(&cfg, "FOO", "bar")
+
+		result, err := config.Image(image, cfg)
+		Expect(err).ToNot(HaveOccurred())
+
+		resultConfigFile, err := result.ConfigFile()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resultConfigFile.Config.Env).To(ContainElement("FOO=bar"))
+		Expect(resultConfigFile.History).To(BeEmpty())
+	})
+})
@@ -0,0 +1,91 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package history
+
+import (
+	"fmt"
+	"regexp"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+)
+
+// Redacted is the replacement text for redacted parts of the history
+const Redacted = "[REDACTED]"
+
+// Image creates a new image based on the input image with the given history.
+// The history has to match the layers of the image, that is one history entry
+// without the empty layer flag per layer. History entries for empty layers
+// can be added or removed.
+func Image(input v1.Image, history []v1.History) (v1.Image, error) {
+	configFile, err := input.ConfigFile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image config file: %w", err)
+	}
+
+	if err := Validate(history, len(configFile.RootFS.DiffIDs)); err != nil {
+		return nil, err
+	}
+
+	configFile = configFile.DeepCopy()
+	configFile.History = history
+
+	return mutate.ConfigFile(input, configFile)
+}
+
+// Validate checks that the history has one non-empty entry per layer
+func Validate(history []v1.History, layers int) error {
+	var nonEmpty int
+	for _, entry := range history {
+		if !entry.EmptyLayer {
+			nonEmpty++
+		}
+	}
+
+	if nonEmpty != layers {
+		return fmt.Errorf("history has %d entries for layers, but the image has %d layers", nonEmpty, layers)
+	}
+
+	return nil
+}
+
+// Redact replaces all matches of the regular expressions in the created by,
+// comment, and author fields, it returns the number of changed entries
+func Redact(history []v1.History, regexes ...*regexp.Regexp) ([]v1.History, int) {
+	var result = make([]v1.History, len(history))
+	var changed int
+	for i, entry := range history {
+		var redacted = entry
+		for _, regex := range regexes {
+			redacted.CreatedBy = regex.ReplaceAllLiteralString(redacted.CreatedBy, Redacted)
+			redacted.Comment = regex.ReplaceAllLiteralString(redacted.Comment, Redacted)
+			redacted.Author = regex.ReplaceAllLiteralString(redacted.Author, Redacted)
+		}
+
+		if redacted != entry {
+			changed++
+		}
+
+		result[i] = redacted
+	}
+
+	return result, changed
+}
@@ -0,0 +1,117 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package history_test
+
+import (
+	"regexp"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/homeport/forklift/pkg/history"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+var _ = Describe("History", func() {
+	var image v1.Image
+
+	BeforeEach(func() {
+		var addenda []mutate.Addendum
+		for _, createdBy := range []string{
+			"COPY base /",
+			"ARG TOKEN=s3cr3t",
+			"RUN |1 TOKEN=s3cr3t ./build.sh",
+		} {
+			var addendum = mutate.Addendum{History: v1.History{CreatedBy: createdBy}}
+			if strings.HasPrefix(createdBy, "ARG") {
+				addendum.History.EmptyLayer = true
+
+			} else {
+				layer, err := random.Layer(256, types.DockerLayer)
+				Expect(err).ToNot(HaveOccurred())
+				addendum.Layer = layer
+			}
+
+			addenda = append(addenda, addendum)
+		}
+
+		var err error
+		image, err = mutate.Append(empty.Image, addenda...)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	entriesOf := func(image v1.Image) []v1.History {
+		GinkgoHelper()
+
+		configFile, err := image.ConfigFile()
+		Expect(err).ToNot(HaveOccurred())
+
+		return configFile.History
+	}
+
+	It("should redact matches of regular expressions", func() {
+		entries, changed := history.Redact(entriesOf(image), regexp.MustCompile(`TOKEN=\S+`))
+		Expect(changed).To(Equal(2))
+
+		result, err := history.Image(image, entries)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entriesOf(result)[2].CreatedBy).To(Equal("RUN |1 [REDACTED] ./build.sh"))
+	})
+
+	It("should allow to drop empty layer entries", func() {
+		entries := entriesOf(image)
+		result, err := history.Image(image, []v1.History{entries[0], entries[2]})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entriesOf(result)).To(HaveLen(2))
+	})
+
+	It("should reject a history that does not match the layers", func() {
+		_, err := history.Image(image, entriesOf(image)[:2])
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should round-trip the history through YAML", func() {
+		data, err := history.ToYAML(image)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring("layer: sha256:"))
+
+		entries, err := history.FromYAML(image, data)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(Equal(entriesOf(image)))
+	})
+
+	It("should detect re-ordered layer entries in YAML", func() {
+		data, err := history.ToYAML(image)
+		Expect(err).ToNot(HaveOccurred())
+
+		var parts = strings.Split(string(data), "\n- ")
+		Expect(parts).To(HaveLen(3))
+
+		_, err = history.FromYAML(image, []byte(strings.Join([]string{"- " + parts[2], parts[1], parts[0][2:]}, "\n- ")))
+		Expect(err).To(MatchError(ContainSubstring("cannot be re-ordered")))
+	})
+})
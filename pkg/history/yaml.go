@@ -0,0 +1,115 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package history
+
+import (
+	"fmt"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"go.yaml.in/yaml/v3"
+)
+
+// entry is the editable form of a history entry, the layer is only
+// informational, so that it is possible to see which entry belongs to
+// which layer, and to detect if entries were re-ordered
+type entry struct {
+	Layer      string    `yaml:"layer,omitempty"`
+	Created    time.Time `yaml:"created,omitempty"`
+	CreatedBy  string    `yaml:"created_by,omitempty"`
+	Author     string    `yaml:"author,omitempty"`
+	Comment    string    `yaml:"comment,omitempty"`
+	EmptyLayer bool      `yaml:"empty_layer,omitempty"`
+}
+
+// ToYAML renders the history of the image as YAML for editing, each entry
+// that belongs to a layer references the layer diffID
+func ToYAML(image v1.Image) ([]byte, error) {
+	configFile, err := image.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries = make([]entry, 0, len(configFile.History))
+	var layerIdx int
+	for _, history := range configFile.History {
+		var e = entry{
+			Created:    history.Created.Time,
+			CreatedBy:  history.CreatedBy,
+			Author:     history.Author,
+			Comment:    history.Comment,
+			EmptyLayer: history.EmptyLayer,
+		}
+
+		if !history.EmptyLayer && layerIdx < len(configFile.RootFS.DiffIDs) {
+			e.Layer = configFile.RootFS.DiffIDs[layerIdx].String()
+			layerIdx++
+		}
+
+		entries = append(entries, e)
+	}
+
+	return yaml.Marshal(entries)
+}
+
+// FromYAML reads the history in the format created by ToYAML and verifies
+// that the entries still reference the layers of the image in order
+func FromYAML(image v1.Image, data []byte) ([]v1.History, error) {
+	configFile, err := image.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []entry
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse history: %w", err)
+	}
+
+	var result = make([]v1.History, 0, len(entries))
+	var layerIdx int
+	for i, e := range entries {
+		if !e.EmptyLayer {
+			if layerIdx >= len(configFile.RootFS.DiffIDs) {
+				return nil, fmt.Errorf("history entry %d references a layer, but the image only has %d layers", i, len(configFile.RootFS.DiffIDs))
+			}
+
+			if expected := configFile.RootFS.DiffIDs[layerIdx].String(); e.Layer != "" && e.Layer != expected {
+				return nil, fmt.Errorf("history entry %d references layer %s, but layer %d is %s, layer entries cannot be re-ordered", i, e.Layer, layerIdx, expected)
+			}
+
+			layerIdx++
+		}
+
+		result = append(result, v1.History{
+			Created:    v1.Time{Time: e.Created},
+			CreatedBy:  e.CreatedBy,
+			Author:     e.Author,
+			Comment:    e.Comment,
+			EmptyLayer: e.EmptyLayer,
+		})
+	}
+
+	if err := Validate(result, len(configFile.RootFS.DiffIDs)); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
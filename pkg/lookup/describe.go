@@ -0,0 +1,158 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package lookup
+
+import (
+	"github.com/homeport/forklift/pkg/misc"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// Description summarizes what an image or index reference points to
+type Description struct {
+	// Source is where the image or index was found, i.e. registry,
+	// docker-daemon, oci, or docker-archive
+	Source string `json:"source,omitempty"`
+
+	// Kind is a human readable name of the manifest format
+	Kind string `json:"kind"`
+
+	// Index is set for image indexes and manifest lists
+	Index bool `json:"index"`
+
+	MediaType types.MediaType `json:"mediaType"`
+
+	// ArtifactType is the artifact type of OCI manifests and indexes, for
+	// manifests without explicit artifact type it is the config media type,
+	// unless it is a regular image config
+	ArtifactType string `json:"artifactType,omitempty"`
+
+	Digest v1.Hash `json:"digest"`
+
+	// ImageID is the image ID of images in the Docker daemon, it is used as
+	// digest if the daemon does not know the manifest digest
+	ImageID *v1.Hash `json:"imageID,omitempty"`
+}
+
+// Describe describes the image or image index
+func Describe(artifact misc.Artifact) (*Description, error) {
+	var result Description
+	if artifact.Index != nil {
+		manifest, err := artifact.Index.IndexManifest()
+		if err != nil {
+			return nil, err
+		}
+
+		result.Index = true
+		result.MediaType = mediaType(manifest.MediaType, types.OCIImageIndex)
+		result.ArtifactType = manifest.ArtifactType
+
+		if result.Digest, err = artifact.Index.Digest(); err != nil {
+			return nil, err
+		}
+
+	} else {
+		manifest, err := artifact.Image.Manifest()
+		if err != nil {
+			return nil, err
+		}
+
+		result.MediaType = mediaType(manifest.MediaType, types.OCIManifestSchema1)
+		result.ArtifactType = manifest.ArtifactType
+		if result.ArtifactType == "" && result.MediaType == types.OCIManifestSchema1 && !manifest.Config.MediaType.IsConfig() {
+			result.ArtifactType = string(manifest.Config.MediaType)
+		}
+
+		if result.Digest, err = artifact.Image.Digest(); err != nil {
+			return nil, err
+		}
+	}
+
+	result.Kind = kind(result.MediaType)
+	return &result, nil
+}
+
+// DescribeDaemon describes the image in the Docker daemon without exporting
+// it, the digest is the manifest digest in the repository of the reference,
+// the digest of the manifest in the daemon, or otherwise the image ID
+func DescribeDaemon(ref name.Reference, image *misc.DaemonImage) *Description {
+	var result = Description{
+		Source:  "docker-daemon",
+		Kind:    "Docker daemon image without manifest",
+		Digest:  image.ID,
+		ImageID: &image.ID,
+	}
+
+	// only the containerd image store keeps the manifest of an image
+	if desc := image.Descriptor; desc != nil {
+		result.Index = desc.MediaType.IsIndex()
+		result.MediaType = desc.MediaType
+		result.ArtifactType = desc.ArtifactType
+		result.Kind = kind(desc.MediaType)
+		result.Digest = desc.Digest
+	}
+
+	if digest, ok := image.RepoDigest(ref); ok {
+		result.Digest = digest
+	}
+
+	return &result
+}
+
+// HasManifestDigest reports whether the digest is a manifest digest, which is
+// not the case for Docker daemon images that only have their image ID
+func (d *Description) HasManifestDigest() bool {
+	return d.ImageID == nil || d.Digest != *d.ImageID
+}
+
+// mediaType returns the media type, or the fallback for manifests that do
+// not state their media type (which is optional for OCI manifests)
+func mediaType(mediaType, fallback types.MediaType) types.MediaType {
+	if mediaType == "" {
+		return fallback
+	}
+
+	return mediaType
+}
+
+func kind(mediaType types.MediaType) string {
+	switch mediaType {
+	case types.OCIImageIndex:
+		return "OCI image index"
+
+	case types.DockerManifestList:
+		return "Docker manifest list"
+
+	case types.OCIManifestSchema1:
+		return "OCI manifest"
+
+	case types.DockerManifestSchema2:
+		return "Docker v2 manifest"
+
+	case types.DockerManifestSchema1, types.DockerManifestSchema1Signed:
+		return "Docker v1 manifest"
+
+	default:
+		return "unknown manifest format"
+	}
+}
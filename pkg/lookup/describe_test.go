@@ -0,0 +1,140 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package lookup_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/homeport/forklift/pkg/lookup"
+	"github.com/homeport/forklift/pkg/misc"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+
+	"github.com/homeport/forklift/internal/imagetest"
+)
+
+var _ = Describe("Describe", func() {
+	It("should describe a Docker v2 manifest", func() {
+		image, err := random.Image(64, 1)
+		Expect(err).ToNot(HaveOccurred())
+
+		desc, err := lookup.Describe(misc.Artifact{Image: image})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(desc.Index).To(BeFalse())
+		Expect(desc.MediaType).To(Equal(types.DockerManifestSchema2))
+		Expect(desc.Kind).To(Equal("Docker v2 manifest"))
+		Expect(desc.ArtifactType).To(BeEmpty())
+		Expect(desc.Digest).To(Equal(imagetest.Must(image.Digest())))
+	})
+
+	It("should describe an OCI manifest", func() {
+		image := mutate.MediaType(imagetest.Must(random.Image(64, 1)), types.OCIManifestSchema1)
+		image = mutate.ConfigMediaType(image, types.OCIConfigJSON)
+
+		desc, err := lookup.Describe(misc.Artifact{Image: image})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(desc.MediaType).To(Equal(types.OCIManifestSchema1))
+		Expect(desc.Kind).To(Equal("OCI manifest"))
+		Expect(desc.ArtifactType).To(BeEmpty())
+	})
+
+	It("should use the config media type as artifact type of OCI artifacts", func() {
+		image := mutate.MediaType(empty.Image, types.OCIManifestSchema1)
+		image = mutate.ConfigMediaType(image, "application/vnd.example.sbom.v1+json")
+
+		desc, err := lookup.Describe(misc.Artifact{Image: image})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(desc.Kind).To(Equal("OCI manifest"))
+		Expect(desc.ArtifactType).To(Equal("application/vnd.example.sbom.v1+json"))
+	})
+
+	It("should describe an image index", func() {
+		index, err := random.Index(64, 1, 2)
+		Expect(err).ToNot(HaveOccurred())
+
+		desc, err := lookup.Describe(misc.Artifact{Index: index})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(desc.Index).To(BeTrue())
+		Expect(desc.MediaType).To(Equal(types.OCIImageIndex))
+		Expect(desc.Kind).To(Equal("OCI image index"))
+		Expect(desc.Digest).To(Equal(imagetest.Must(index.Digest())))
+	})
+
+	It("should describe a Docker manifest list", func() {
+		index := mutate.IndexMediaType(imagetest.Must(random.Index(64, 1, 2)), types.DockerManifestList)
+
+		desc, err := lookup.Describe(misc.Artifact{Index: index})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(desc.Kind).To(Equal("Docker manifest list"))
+	})
+
+	Context("images in the Docker daemon", func() {
+		var (
+			id   = imagetest.Must(v1.NewHash("sha256:" + strings.Repeat("a", 64)))
+			repo = imagetest.Must(v1.NewHash("sha256:" + strings.Repeat("b", 64)))
+			ref  = imagetest.Must(name.ParseReference("alpine:3"))
+		)
+
+		It("should use the repo digest of the repository of the reference", func() {
+			image := &misc.DaemonImage{
+				ID: id,
+				RepoDigests: []name.Digest{
+					imagetest.Must(name.NewDigest("example.com/alpine@sha256:" + strings.Repeat("c", 64))),
+					imagetest.Must(name.NewDigest("alpine@" + repo.String())),
+				},
+			}
+
+			desc := lookup.DescribeDaemon(ref, image)
+			Expect(desc.Source).To(Equal("docker-daemon"))
+			Expect(desc.Digest).To(Equal(repo))
+			Expect(*desc.ImageID).To(Equal(id))
+			Expect(desc.MediaType).To(BeEmpty())
+			Expect(desc.HasManifestDigest()).To(BeTrue())
+		})
+
+		It("should fall back to the image ID without repo digest", func() {
+			desc := lookup.DescribeDaemon(ref, &misc.DaemonImage{ID: id})
+			Expect(desc.Digest).To(Equal(id))
+			Expect(desc.Kind).To(Equal("Docker daemon image without manifest"))
+			Expect(desc.HasManifestDigest()).To(BeFalse())
+		})
+
+		It("should use the manifest of the containerd image store", func() {
+			image := &misc.DaemonImage{
+				ID:         id,
+				Descriptor: &v1.Descriptor{MediaType: types.OCIImageIndex, Digest: repo},
+			}
+
+			desc := lookup.DescribeDaemon(ref, image)
+			Expect(desc.Digest).To(Equal(repo))
+			Expect(desc.Index).To(BeTrue())
+			Expect(desc.Kind).To(Equal("OCI image index"))
+		})
+	})
+})
@@ -0,0 +1,147 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package lookup
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var semverPattern = regexp.MustCompile(`^v?(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+
+// FilterTags returns the tags that match the regular expression
+func FilterTags(tags []string, pattern string) ([]string, error) {
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for _, tag := range tags {
+		if regex.MatchString(tag) {
+			result = append(result, tag)
+		}
+	}
+
+	return result, nil
+}
+
+// SortTags sorts tags that are semantic versions (with an optional v prefix
+// and optional minor and patch version) by their version in ascending
+// order, followed by all other tags in alphabetical order
+func SortTags(tags []string) {
+	slices.SortStableFunc(tags, func(a, b string) int {
+		va, aok := parseVersion(a)
+		vb, bok := parseVersion(b)
+		switch {
+		case aok && bok:
+			if result := va.compare(vb); result != 0 {
+				return result
+			}
+
+			return strings.Compare(a, b)
+
+		case aok:
+			return -1
+
+		case bok:
+			return 1
+
+		default:
+			return strings.Compare(a, b)
+		}
+	})
+}
+
+type version struct {
+	numbers    [3]int
+	prerelease []string
+}
+
+func parseVersion(tag string) (version, bool) {
+	var match = semverPattern.FindStringSubmatch(tag)
+	if match == nil {
+		return version{}, false
+	}
+
+	var result version
+	for i := range result.numbers {
+		if match[i+1] != "" {
+			result.numbers[i], _ = strconv.Atoi(match[i+1])
+		}
+	}
+
+	if match[4] != "" {
+		result.prerelease = strings.Split(match[4], ".")
+	}
+
+	return result, true
+}
+
+// compare compares versions according to the semantic version precedence,
+// where a pre-release has a lower precedence than the release
+func (v version) compare(other version) int {
+	for i := range v.numbers {
+		if result := cmp.Compare(v.numbers[i], other.numbers[i]); result != 0 {
+			return result
+		}
+	}
+
+	switch {
+	case len(v.prerelease) == 0 && len(other.prerelease) == 0:
+		return 0
+
+	case len(v.prerelease) == 0:
+		return 1
+
+	case len(other.prerelease) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(v.prerelease) && i < len(other.prerelease); i++ {
+		a, aErr := strconv.Atoi(v.prerelease[i])
+		b, bErr := strconv.Atoi(other.prerelease[i])
+
+		var result int
+		switch {
+		case aErr == nil && bErr == nil:
+			result = cmp.Compare(a, b)
+
+		case aErr == nil:
+			result = -1
+
+		case bErr == nil:
+			result = 1
+
+		default:
+			result = strings.Compare(v.prerelease[i], other.prerelease[i])
+		}
+
+		if result != 0 {
+			return result
+		}
+	}
+
+	return cmp.Compare(len(v.prerelease), len(other.prerelease))
+}
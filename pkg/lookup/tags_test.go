@@ -0,0 +1,42 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package lookup_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/homeport/forklift/pkg/lookup"
+)
+
+var _ = Describe("Tags", func() {
+	It("should sort semantic versions by precedence followed by other tags", func() {
+		var tags = []string{"latest", "v1.10.0", "1.2", "1.2.0-rc.10", "1.2.0-rc.2", "1.2.0-alpha", "v1.9.1", "edge", "2"}
+		lookup.SortTags(tags)
+		Expect(tags).To(Equal([]string{"1.2.0-alpha", "1.2.0-rc.2", "1.2.0-rc.10", "1.2", "v1.9.1", "v1.10.0", "2", "edge", "latest"}))
+	})
+
+	It("should filter tags using a regular expression", func() {
+		tags, err := lookup.FilterTags([]string{"3.19", "3.20", "edge", "3.20-rc1"}, `^3\.\d+$`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tags).To(Equal([]string{"3.19", "3.20"}))
+	})
+})
@@ -0,0 +1,140 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package misc
+
+import (
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	specsv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// MissingBaseNameError is returned for images that note the digest of their
+// base image, but not its name, so that the base image cannot be loaded
+type MissingBaseNameError struct {
+	Digest string
+}
+
+func (e *MissingBaseNameError) Error() string {
+	return fmt.Sprintf("base image digest %s is noted without the base image name (%s)", e.Digest, specsv1.AnnotationBaseImageName)
+}
+
+// BaseImageReference returns the reference of the base image as noted in the
+// image manifest annotations (or config labels), or nil if there is none, a
+// MissingBaseNameError is returned if there is only the base image digest
+func BaseImageReference(image v1.Image) (name.Reference, error) {
+	var lookup = func(key string) string {
+		if manifest, err := image.Manifest(); err == nil && manifest.Annotations[key] != "" {
+			return manifest.Annotations[key]
+		}
+
+		if configFile, err := image.ConfigFile(); err == nil {
+			return configFile.Config.Labels[key]
+		}
+
+		return ""
+	}
+
+	var baseName = lookup(specsv1.AnnotationBaseImageName)
+	var baseDigest = lookup(specsv1.AnnotationBaseImageDigest)
+
+	switch {
+	case baseName == "" && baseDigest != "":
+		return nil, &MissingBaseNameError{Digest: baseDigest}
+
+	case baseName == "":
+		return nil, nil
+
+	case baseDigest != "":
+		repo, err := name.ParseReference(baseName)
+		if err != nil {
+			return nil, err
+		}
+
+		return name.NewDigest(repo.Context().String() + "@" + baseDigest)
+
+	default:
+		return name.ParseReference(baseName)
+	}
+}
+
+// BaseBoundary verifies that the bottom layers of the image match the layers
+// of the base image by their diffIDs and returns the number of leading
+// history entries (see Layers) that belong to the base image
+func BaseBoundary(image v1.Image, base v1.Image) (int, error) {
+	imageConfigFile, err := image.ConfigFile()
+	if err != nil {
+		return 0, err
+	}
+
+	baseConfigFile, err := base.ConfigFile()
+	if err != nil {
+		return 0, err
+	}
+
+	var imageDiffIDs = imageConfigFile.RootFS.DiffIDs
+	var baseDiffIDs = baseConfigFile.RootFS.DiffIDs
+
+	if len(baseDiffIDs) > len(imageDiffIDs) {
+		return 0, fmt.Errorf("base image has more layers (%d) than the image (%d)", len(baseDiffIDs), len(imageDiffIDs))
+	}
+
+	for i := range baseDiffIDs {
+		if imageDiffIDs[i] != baseDiffIDs[i] {
+			return 0, fmt.Errorf("layer %d of the image (%s) does not match the base image layer (%s)", i, imageDiffIDs[i], baseDiffIDs[i])
+		}
+	}
+
+	if len(baseDiffIDs) == 0 {
+		return 0, nil
+	}
+
+	var nonEmpty = func(history []v1.History) (count int) {
+		for _, entry := range history {
+			if !entry.EmptyLayer {
+				count++
+			}
+		}
+
+		return count
+	}
+
+	// prefer the base image history length, so that trailing empty layer
+	// entries of the base image (i.e. ENV, CMD) are considered, too
+	var imageHistory = imageConfigFile.History
+	if n := len(baseConfigFile.History); n > 0 && n <= len(imageHistory) && nonEmpty(imageHistory[:n]) == len(baseDiffIDs) {
+		return n, nil
+	}
+
+	var count int
+	for i, entry := range imageHistory {
+		if !entry.EmptyLayer {
+			count++
+		}
+
+		if count == len(baseDiffIDs) {
+			return i + 1, nil
+		}
+	}
+
+	return 0, fmt.Errorf("image history does not cover all %d base image layers", len(baseDiffIDs))
+}
@@ -0,0 +1,72 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package misc_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/homeport/forklift/pkg/misc"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	specsv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+var _ = Describe("Base image annotations", func() {
+	const digest = "sha256:0000000000000000000000000000000000000000000000000000000000000000"
+
+	annotated := func(annotations map[string]string) v1.Image {
+		GinkgoHelper()
+
+		image, err := random.Image(64, 1)
+		Expect(err).ToNot(HaveOccurred())
+
+		return mutate.Annotations(image, annotations).(v1.Image)
+	}
+
+	It("should use the name and digest of the base image", func() {
+		ref, err := misc.BaseImageReference(annotated(map[string]string{
+			specsv1.AnnotationBaseImageName:   "example.com/base:1.0",
+			specsv1.AnnotationBaseImageDigest: digest,
+		}))
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ref.String()).To(Equal("example.com/base@" + digest))
+	})
+
+	It("should return nil if there is no base image annotation", func() {
+		ref, err := misc.BaseImageReference(annotated(nil))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ref).To(BeNil())
+	})
+
+	It("should report a base image digest without name", func() {
+		_, err := misc.BaseImageReference(annotated(map[string]string{
+			specsv1.AnnotationBaseImageDigest: digest,
+		}))
+
+		var missing *misc.MissingBaseNameError
+		Expect(err).To(BeAssignableToTypeOf(missing))
+		Expect(err.Error()).To(ContainSubstring(digest))
+	})
+})
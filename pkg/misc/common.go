@@ -22,20 +22,49 @@ package misc
 
 import (
 	"context"
+	"io"
+	"log"
+	"net/http"
 
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/homeport/forklift/pkg/cache"
+	"github.com/homeport/forklift/pkg/registries"
 )
 
+// Keychain is used to look up the registry credentials
+var Keychain authn.Keychain = authn.DefaultKeychain
+
+// Transport is used for all registry requests
+var Transport http.RoundTripper = remote.DefaultTransport
+
+// Cache stores blobs loaded from registries, no cache is used if it is nil
+var Cache *cache.Cache
+
+// Log receives details about where images come from, it discards everything
+// unless it is given an output
+var Log = log.New(io.Discard, "", 0)
+
 func RemoteOptionsFromRef(ctx context.Context, ref name.Reference) ([]remote.Option, error) {
-	auth, err := authn.DefaultKeychain.ResolveContext(ctx, ref.Context())
+	auth, err := authn.Resolve(ctx, Keychain, ref.Context())
 	if err != nil {
 		return nil, err
 	}
 
-	return []remote.Option{
+	var opts = []remote.Option{
 		remote.WithContext(ctx),
 		remote.WithAuth(auth),
-	}, nil
+		remote.WithTransport(Transport),
+	}
+
+	// the registries transport retries failed requests itself
+	if _, ok := Transport.(*registries.Transport); ok {
+		opts = append(opts,
+			remote.WithRetryPredicate(func(error) bool { return false }),
+			remote.WithRetryStatusCodes(),
+		)
+	}
+
+	return opts, nil
 }
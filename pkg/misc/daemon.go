@@ -0,0 +1,101 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package misc
+
+import (
+	"context"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/moby/moby/client"
+)
+
+// DaemonImage is what the Docker daemon knows about an image without
+// exporting it (docker save)
+type DaemonImage struct {
+	// ID is the image ID, which is the digest of the image config
+	ID v1.Hash
+
+	// RepoDigests are the manifest digests of the image in the registries
+	// it was pulled from or pushed to
+	RepoDigests []name.Digest
+
+	// Descriptor of the manifest or index, only the containerd image store
+	// keeps the manifest of an image
+	Descriptor *v1.Descriptor
+}
+
+// InspectDaemon inspects the image in the Docker daemon
+func InspectDaemon(ctx context.Context, ref name.Reference) (*DaemonImage, error) {
+	apiClient, err := client.New(client.FromEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = apiClient.Close() }()
+
+	inspect, err := apiClient.ImageInspect(ctx, ref.String())
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := v1.NewHash(inspect.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var result = DaemonImage{ID: id}
+	for _, repoDigest := range inspect.RepoDigests {
+		if digest, err := name.NewDigest(repoDigest); err == nil {
+			result.RepoDigests = append(result.RepoDigests, digest)
+		}
+	}
+
+	if desc := inspect.Descriptor; desc != nil {
+		digest, err := v1.NewHash(desc.Digest.String())
+		if err != nil {
+			return nil, err
+		}
+
+		result.Descriptor = &v1.Descriptor{
+			MediaType:    types.MediaType(desc.MediaType),
+			Size:         desc.Size,
+			Digest:       digest,
+			ArtifactType: desc.ArtifactType,
+		}
+	}
+
+	return &result, nil
+}
+
+// RepoDigest returns the manifest digest of the image in the repository of
+// the reference, if the daemon knows it
+func (d *DaemonImage) RepoDigest(ref name.Reference) (v1.Hash, bool) {
+	for _, digest := range d.RepoDigests {
+		if digest.Context().Name() == ref.Context().Name() {
+			hash, err := v1.NewHash(digest.DigestStr())
+			return hash, err == nil
+		}
+	}
+
+	return v1.Hash{}, false
+}
@@ -28,24 +28,46 @@ import (
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/daemon"
-	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
 )
 
-func LoadImage(ctx context.Context, ref name.Reference) (v1.Image, error) {
-	if image, err := daemon.Image(ref, daemon.WithContext(ctx)); err == nil {
-		return image, nil
-	}
+// DefaultPlatform is used to select the image of an image index
+var DefaultPlatform = v1.Platform{OS: "linux", Architecture: "amd64"}
 
-	opts, err := RemoteOptionsFromRef(ctx, ref)
+// LoadImage loads the image from the Docker daemon, or from the registry if
+// the daemon does not have it (see Load), for an image index the image of
+// the default platform is used
+func LoadImage(ctx context.Context, ref name.Reference) (v1.Image, error) {
+	artifact, err := Load(ctx, Location{Reference: ref})
 	if err != nil {
 		return nil, err
 	}
 
-	return remote.Image(ref, opts...)
+	if artifact.Index != nil {
+		if artifact, err = artifact.Select(DefaultPlatform); err != nil {
+			return nil, err
+		}
+	}
+
+	return artifact.Image, nil
+}
+
+// EmptyImage creates an image without layers and history using a copy of
+// the config file, so that layers can be appended to it
+func EmptyImage(configFile *v1.ConfigFile) (v1.Image, error) {
+	// reset config details so that it can be used in a fresh image
+	configFile = configFile.DeepCopy()
+	configFile.RootFS.DiffIDs = []v1.Hash{}
+	configFile.History = []v1.History{}
+
+	return mutate.ConfigFile(empty.Image, configFile)
 }
 
-func SaveImage(tag name.Tag, img v1.Image, options ...daemon.Option) error {
-	response, err := daemon.Write(tag, img, options...)
+// SaveImage writes the image to the Docker daemon, the write is cancelled
+// with the context
+func SaveImage(ctx context.Context, tag name.Tag, img v1.Image) error {
+	response, err := daemon.Write(tag, img, daemon.WithContext(ctx))
 	if err != nil {
 		fmt.Fprintln(os.Stderr, response)
 		return fmt.Errorf("failed to write image: %w", err)
@@ -24,6 +24,7 @@ import (
 	"fmt"
 
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
 )
 
 // Layer is a go-containerregistry v1.Layer equivalent, but with optional history attached
@@ -32,6 +33,9 @@ type Layer struct {
 
 	LayerIdx *int
 	History  *v1.History
+
+	// Base indicates that the layer belongs to the base image
+	Base bool
 }
 
 func Layers(image v1.Image) ([]Layer, error) {
@@ -55,7 +59,7 @@ func Layers(image v1.Image) ([]Layer, error) {
 	var result []Layer
 
 	var nonEmptyLayerIdx int
-	for i := len(configFile.History) - 1; i >= 0; i-- {
+	for i := range configFile.History {
 		var entry = configFile.History[i]
 
 		if entry.EmptyLayer {
@@ -64,6 +68,10 @@ func Layers(image v1.Image) ([]Layer, error) {
 			})
 
 		} else {
+			if nonEmptyLayerIdx >= len(layers) {
+				return nil, fmt.Errorf("history references more layers than the image has (%d)", len(layers))
+			}
+
 			result = append(result, Layer{
 				LayerIdx: ptr(nonEmptyLayerIdx),
 				Layer:    layers[nonEmptyLayerIdx],
@@ -76,3 +84,38 @@ func Layers(image v1.Image) ([]Layer, error) {
 
 	return result, nil
 }
+
+// Addenda returns the layers of the image with their history, an image
+// without history results in one addendum per layer without history
+func Addenda(image v1.Image) ([]mutate.Addendum, error) {
+	configFile, err := image.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(configFile.History) == 0 {
+		layers, err := image.Layers()
+		if err != nil {
+			return nil, err
+		}
+
+		var result = make([]mutate.Addendum, len(layers))
+		for i := range layers {
+			result[i] = mutate.Addendum{Layer: layers[i]}
+		}
+
+		return result, nil
+	}
+
+	layers, err := Layers(image)
+	if err != nil {
+		return nil, err
+	}
+
+	var result = make([]mutate.Addendum, len(layers))
+	for i := range layers {
+		result[i] = mutate.Addendum{Layer: layers[i].Layer, History: *layers[i].History}
+	}
+
+	return result, nil
+}
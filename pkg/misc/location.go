@@ -0,0 +1,375 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package misc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/daemon"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	specsv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Scheme is the kind of storage of an image location
+type Scheme string
+
+// Supported schemes, a location without scheme refers to the Docker daemon
+// or a registry (see LoadImage)
+const (
+	SchemeAuto     Scheme = ""
+	SchemeRegistry Scheme = "docker://"
+	SchemeDaemon   Scheme = "docker-daemon:"
+	SchemeOCI      Scheme = "oci:"
+	SchemeArchive  Scheme = "docker-archive:"
+)
+
+// Location is where an image or image index is stored
+type Location struct {
+	Scheme Scheme
+
+	// Reference of the image in a registry or daemon, or the optional
+	// reference of an image in a Docker archive
+	Reference name.Reference
+
+	// Path of the OCI layout directory or Docker archive file
+	Path string
+
+	// Tag of the entry in an OCI layout (org.opencontainers.image.ref.name)
+	Tag string
+}
+
+// ParseLocation parses an image location, which is an image reference with
+// an optional scheme prefix:
+//
+//	docker://<reference>                registry
+//	docker-daemon:<reference>           Docker daemon
+//	oci:<path>[:<tag>]                  OCI layout directory
+//	docker-archive:<path>[:<reference>] Docker archive (docker save)
+func ParseLocation(location string) (Location, error) {
+	for _, scheme := range []Scheme{SchemeRegistry, SchemeDaemon} {
+		if rest, ok := strings.CutPrefix(location, string(scheme)); ok {
+			ref, err := name.ParseReference(rest)
+			return Location{Scheme: scheme, Reference: ref}, err
+		}
+	}
+
+	for _, scheme := range []Scheme{SchemeOCI, SchemeArchive} {
+		if rest, ok := strings.CutPrefix(location, string(scheme)); ok {
+			path, refName, _ := strings.Cut(rest, ":")
+			if path == "" {
+				return Location{}, fmt.Errorf("location %s has no path", location)
+			}
+
+			var result = Location{Scheme: scheme, Path: path}
+			switch {
+			case refName == "":
+				return result, nil
+
+			case scheme == SchemeOCI:
+				result.Tag = refName
+				return result, nil
+
+			default:
+				ref, err := name.ParseReference(refName)
+				result.Reference = ref
+				return result, err
+			}
+		}
+	}
+
+	ref, err := name.ParseReference(location)
+	return Location{Reference: ref}, err
+}
+
+func (l Location) String() string {
+	switch l.Scheme {
+	case SchemeOCI:
+		if l.Tag != "" {
+			return string(l.Scheme) + l.Path + ":" + l.Tag
+		}
+
+		return string(l.Scheme) + l.Path
+
+	case SchemeArchive:
+		if l.Reference != nil {
+			return string(l.Scheme) + l.Path + ":" + l.Reference.String()
+		}
+
+		return string(l.Scheme) + l.Path
+
+	default:
+		return string(l.Scheme) + l.Reference.String()
+	}
+}
+
+// Artifact is either an image or an image index
+type Artifact struct {
+	Image v1.Image
+	Index v1.ImageIndex
+}
+
+// Digest returns the digest of the image or image index
+func (a Artifact) Digest() (v1.Hash, error) {
+	if a.Index != nil {
+		return a.Index.Digest()
+	}
+
+	return a.Image.Digest()
+}
+
+// RawManifest returns the manifest of the image or image index as-is
+func (a Artifact) RawManifest() ([]byte, error) {
+	if a.Index != nil {
+		return a.Index.RawManifest()
+	}
+
+	return a.Image.RawManifest()
+}
+
+// Select returns the image of the index that matches the platform, an
+// image is returned as-is if it matches the platform
+func (a Artifact) Select(platform v1.Platform) (Artifact, error) {
+	if a.Index == nil {
+		configFile, err := a.Image.ConfigFile()
+		if err != nil {
+			return Artifact{}, err
+		}
+
+		if configFile.Platform() == nil || !configFile.Platform().Satisfies(platform) {
+			return Artifact{}, fmt.Errorf("image does not match platform %s", platform)
+		}
+
+		return a, nil
+	}
+
+	manifest, err := a.Index.IndexManifest()
+	if err != nil {
+		return Artifact{}, err
+	}
+
+	for _, desc := range manifest.Manifests {
+		if desc.Platform == nil || !desc.Platform.Satisfies(platform) {
+			continue
+		}
+
+		if desc.MediaType.IsIndex() {
+			child, err := a.Index.ImageIndex(desc.Digest)
+			if err != nil {
+				return Artifact{}, err
+			}
+
+			return Artifact{Index: child}.Select(platform)
+		}
+
+		image, err := a.Index.Image(desc.Digest)
+		return Artifact{Image: image}, err
+	}
+
+	return Artifact{}, fmt.Errorf("image index has no entry for platform %s", platform)
+}
+
+// Load loads the image or image index from the location, a location without
+// scheme is looked up in the Docker daemon first and then in the registry
+func Load(ctx context.Context, location Location) (Artifact, error) {
+	switch location.Scheme {
+	case SchemeAuto:
+		if image, err := daemon.Image(location.Reference, daemon.WithContext(ctx)); err == nil {
+			Log.Printf("%s served by the Docker daemon", location.Reference)
+			return Artifact{Image: image}, nil
+		}
+
+		return loadRemote(ctx, location.Reference)
+
+	case SchemeRegistry:
+		return loadRemote(ctx, location.Reference)
+
+	case SchemeDaemon:
+		image, err := daemon.Image(location.Reference, daemon.WithContext(ctx))
+		return Artifact{Image: image}, err
+
+	case SchemeOCI:
+		return loadLayout(location)
+
+	case SchemeArchive:
+		var tag *name.Tag
+		if t, ok := location.Reference.(name.Tag); ok {
+			tag = &t
+		}
+
+		image, err := tarball.ImageFromPath(location.Path, tag)
+		return Artifact{Image: image}, err
+
+	default:
+		return Artifact{}, fmt.Errorf("unsupported scheme %q", location.Scheme)
+	}
+}
+
+// Write writes the image or image index to the location, a location without
+// scheme refers to a registry. Registry writes skip blobs that already exist
+// and mount blobs from other repositories of the same registry.
+func Write(ctx context.Context, location Location, artifact Artifact) error {
+	switch location.Scheme {
+	case SchemeAuto, SchemeRegistry:
+		opts, err := RemoteOptionsFromRef(ctx, location.Reference)
+		if err != nil {
+			return err
+		}
+
+		if artifact.Index != nil {
+			return remote.WriteIndex(location.Reference, artifact.Index, opts...)
+		}
+
+		return remote.Write(location.Reference, artifact.Image, opts...)
+
+	case SchemeDaemon:
+		tag, ok := location.Reference.(name.Tag)
+		if !ok {
+			return fmt.Errorf("writing to the Docker daemon requires a tag, not %s", location.Reference)
+		}
+
+		if artifact.Index != nil {
+			return errors.New("the Docker daemon does not support image indexes, select a platform")
+		}
+
+		return SaveImage(ctx, tag, artifact.Image)
+
+	case SchemeOCI:
+		return writeLayout(location, artifact)
+
+	case SchemeArchive:
+		if artifact.Index != nil {
+			return errors.New("Docker archives do not support image indexes, select a platform")
+		}
+
+		if location.Reference == nil {
+			return errors.New("writing a Docker archive requires a reference, i.e. docker-archive:<path>:<reference>")
+		}
+
+		return tarball.WriteToFile(location.Path, location.Reference, artifact.Image)
+
+	default:
+		return fmt.Errorf("unsupported scheme %q", location.Scheme)
+	}
+}
+
+func loadRemote(ctx context.Context, ref name.Reference) (Artifact, error) {
+	if Cache != nil {
+		return FromEndpoints(ctx, ref, func(ref name.Reference) (Artifact, error) {
+			opts, err := RemoteOptionsFromRef(ctx, ref)
+			if err != nil {
+				return Artifact{}, err
+			}
+
+			image, index, err := Cache.Remote(ref, opts...)
+			return Artifact{Image: image, Index: index}, err
+		})
+	}
+
+	desc, err := FromEndpoints(ctx, ref, func(ref name.Reference) (*remote.Descriptor, error) {
+		opts, err := RemoteOptionsFromRef(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+
+		return remote.Get(ref, opts...)
+	})
+
+	if err != nil {
+		return Artifact{}, err
+	}
+
+	if desc.MediaType.IsIndex() {
+		index, err := desc.ImageIndex()
+		return Artifact{Index: index}, err
+	}
+
+	image, err := desc.Image()
+	return Artifact{Image: image}, err
+}
+
+// loadLayout loads the image index of the OCI layout, or the entry with the
+// given tag (annotation org.opencontainers.image.ref.name)
+func loadLayout(location Location) (Artifact, error) {
+	index, err := layout.ImageIndexFromPath(location.Path)
+	if err != nil {
+		return Artifact{}, err
+	}
+
+	if location.Tag == "" {
+		return Artifact{Index: index}, nil
+	}
+
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		return Artifact{}, err
+	}
+
+	for _, desc := range manifest.Manifests {
+		if desc.Annotations[specsv1.AnnotationRefName] != location.Tag {
+			continue
+		}
+
+		if desc.MediaType.IsIndex() {
+			child, err := index.ImageIndex(desc.Digest)
+			return Artifact{Index: child}, err
+		}
+
+		image, err := index.Image(desc.Digest)
+		return Artifact{Image: image}, err
+	}
+
+	return Artifact{}, fmt.Errorf("OCI layout %s has no entry with tag %s", location.Path, location.Tag)
+}
+
+// writeLayout adds the image or image index to the OCI layout, which is
+// created if required, a tag replaces any existing entry with the same tag
+func writeLayout(location Location, artifact Artifact) error {
+	path, err := layout.FromPath(location.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		path, err = layout.Write(location.Path, empty.Index)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	var opts []layout.Option
+	var matcher = func(v1.Descriptor) bool { return false }
+	if location.Tag != "" {
+		opts = append(opts, layout.WithAnnotations(map[string]string{specsv1.AnnotationRefName: location.Tag}))
+		matcher = func(desc v1.Descriptor) bool { return desc.Annotations[specsv1.AnnotationRefName] == location.Tag }
+	}
+
+	if artifact.Index != nil {
+		return path.ReplaceIndex(artifact.Index, matcher, opts...)
+	}
+
+	return path.ReplaceImage(artifact.Image, matcher, opts...)
+}
@@ -0,0 +1,144 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package misc_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/homeport/forklift/pkg/misc"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+
+	"github.com/homeport/forklift/internal/imagetest"
+)
+
+var _ = Describe("Location", func() {
+	Context("parsing", func() {
+		It("should parse all supported schemes", func() {
+			location, err := misc.ParseLocation("docker://alpine:3")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(location.Scheme).To(Equal(misc.SchemeRegistry))
+			Expect(location.Reference.String()).To(Equal("alpine:3"))
+
+			location, err = misc.ParseLocation("docker-daemon:alpine:3")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(location.Scheme).To(Equal(misc.SchemeDaemon))
+
+			location, err = misc.ParseLocation("oci:/tmp/layout:v1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(location.Scheme).To(Equal(misc.SchemeOCI))
+			Expect(location.Path).To(Equal("/tmp/layout"))
+			Expect(location.Tag).To(Equal("v1"))
+
+			location, err = misc.ParseLocation("docker-archive:image.tar:foo/bar:1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(location.Scheme).To(Equal(misc.SchemeArchive))
+			Expect(location.Path).To(Equal("image.tar"))
+			Expect(location.Reference.String()).To(Equal("foo/bar:1"))
+
+			location, err = misc.ParseLocation("alpine:3")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(location.Scheme).To(Equal(misc.SchemeAuto))
+			Expect(location.String()).To(Equal("alpine:3"))
+		})
+
+		It("should fail on locations without path", func() {
+			_, err := misc.ParseLocation("oci:")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("copying", func() {
+		var (
+			ctx   = context.Background()
+			index v1.ImageIndex
+			dir   string
+		)
+
+		var parse = func(location string) misc.Location {
+			GinkgoHelper()
+
+			result, err := misc.ParseLocation(location)
+			Expect(err).ToNot(HaveOccurred())
+			return result
+		}
+
+		BeforeEach(func() {
+			image, err := random.Image(256, 1)
+			Expect(err).ToNot(HaveOccurred())
+
+			index, err = random.Index(256, 1, 1)
+			Expect(err).ToNot(HaveOccurred())
+
+			index = mutate.AppendManifests(index, mutate.IndexAddendum{
+				Add:        image,
+				Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "arm64"}},
+			})
+
+			dir = GinkgoT().TempDir()
+		})
+
+		It("should copy a whole index between OCI layouts and registries", func() {
+			server := httptest.NewServer(registry.New())
+			DeferCleanup(server.Close)
+			var host = strings.TrimPrefix(server.URL, "http://")
+
+			Expect(misc.Write(ctx, parse("oci:"+filepath.Join(dir, "layout")+":v1"), misc.Artifact{Index: index})).To(Succeed())
+
+			artifact, err := misc.Load(ctx, parse("oci:"+filepath.Join(dir, "layout")+":v1"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(artifact.Index).ToNot(BeNil())
+
+			Expect(misc.Write(ctx, parse("docker://"+host+"/test/index:v1"), artifact)).To(Succeed())
+
+			copied, err := misc.Load(ctx, parse("docker://"+host+"/test/index:v1"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(copied.Index).ToNot(BeNil())
+			Expect(copied.Digest()).To(Equal(imagetest.Must(index.Digest())))
+		})
+
+		It("should copy a single platform into a Docker archive", func() {
+			artifact, err := misc.Artifact{Index: index}.Select(v1.Platform{OS: "linux", Architecture: "arm64"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(artifact.Image).ToNot(BeNil())
+
+			var archive = "docker-archive:" + filepath.Join(dir, "image.tar") + ":foo/bar:1"
+			Expect(misc.Write(ctx, parse(archive), artifact)).To(Succeed())
+
+			copied, err := misc.Load(ctx, parse(archive))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(copied.Image.ConfigName()).To(Equal(imagetest.Must(artifact.Image.ConfigName())))
+		})
+
+		It("should refuse to write an index into a Docker archive", func() {
+			Expect(misc.Write(ctx, parse("docker-archive:"+filepath.Join(dir, "image.tar")+":foo/bar:1"), misc.Artifact{Index: index})).ToNot(Succeed())
+		})
+	})
+})
@@ -0,0 +1,72 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package misc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/homeport/forklift/pkg/registries"
+)
+
+// Mirrors are the rewrite rules applied to registry references
+var Mirrors registries.Mirrors
+
+// FromEndpoints calls fn with the reference rewritten for each endpoint of
+// the mirror rules in order, until one succeeds
+func FromEndpoints[T any](ctx context.Context, ref name.Reference, fn func(name.Reference) (T, error)) (T, error) {
+	var zero T
+	endpoints, err := Mirrors.Endpoints(ref.Context())
+	if err != nil {
+		return zero, err
+	}
+
+	var errs []error
+	for _, endpoint := range endpoints {
+		var target = withRepository(ref, endpoint)
+		result, err := fn(target)
+		if err == nil {
+			Log.Printf("%s served by %s", ref, target)
+			return result, nil
+		}
+
+		if len(endpoints) == 1 || ctx.Err() != nil {
+			return zero, err
+		}
+
+		Log.Printf("%s failed for %s: %v", target, ref, err)
+		errs = append(errs, fmt.Errorf("%s: %w", target, err))
+	}
+
+	return zero, fmt.Errorf("no endpoint could serve %s: %w", ref, errors.Join(errs...))
+}
+
+// withRepository returns the reference with the same tag or digest in the
+// given repository
+func withRepository(ref name.Reference, repo name.Repository) name.Reference {
+	if digest, ok := ref.(name.Digest); ok {
+		return repo.Digest(digest.DigestStr())
+	}
+
+	return repo.Tag(ref.Identifier())
+}
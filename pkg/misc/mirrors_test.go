@@ -0,0 +1,85 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package misc_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/homeport/forklift/pkg/misc"
+	"github.com/homeport/forklift/pkg/registries"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+
+	"github.com/homeport/forklift/internal/imagetest"
+)
+
+var _ = Describe("Mirrors", func() {
+	var origin, mirror string
+
+	var start = func() string {
+		GinkgoHelper()
+
+		server := httptest.NewServer(registry.New())
+		DeferCleanup(server.Close)
+		return strings.TrimPrefix(server.URL, "http://")
+	}
+
+	BeforeEach(func() {
+		origin, mirror = start(), start()
+
+		var original = misc.Mirrors
+		DeferCleanup(func() { misc.Mirrors = original })
+
+		misc.Mirrors = registries.Mirrors{{From: origin + "/*", To: []string{mirror + "/cache/*"}}}
+	})
+
+	It("should load images from the mirror", func() {
+		image := imagetest.Must(random.Image(64, 1))
+		Expect(remote.Write(imagetest.Must(name.NewTag(mirror+"/cache/foo/bar:1")), image)).To(Succeed())
+
+		artifact, err := misc.Load(context.Background(), misc.Location{Scheme: misc.SchemeRegistry, Reference: imagetest.Must(name.NewTag(origin + "/foo/bar:1"))})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(artifact.Digest()).To(Equal(imagetest.Must(image.Digest())))
+	})
+
+	It("should fall back to the original registry", func() {
+		image := imagetest.Must(random.Image(64, 1))
+		Expect(remote.Write(imagetest.Must(name.NewTag(origin+"/foo/bar:1")), image)).To(Succeed())
+
+		artifact, err := misc.Load(context.Background(), misc.Location{Scheme: misc.SchemeRegistry, Reference: imagetest.Must(name.NewTag(origin + "/foo/bar:1"))})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(artifact.Digest()).To(Equal(imagetest.Must(image.Digest())))
+	})
+
+	It("should report all endpoints if none can serve the image", func() {
+		_, err := misc.Load(context.Background(), misc.Location{Scheme: misc.SchemeRegistry, Reference: imagetest.Must(name.NewTag(origin + "/foo/bar:1"))})
+		Expect(err).To(MatchError(ContainSubstring("no endpoint could serve")))
+		Expect(err).To(MatchError(ContainSubstring(mirror + "/cache/foo/bar:1")))
+	})
+})
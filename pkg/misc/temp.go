@@ -0,0 +1,70 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package misc
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// TempImage is an image with layers that are read from temporary files, it
+// has to be closed after it was written to remove the files
+type TempImage struct {
+	v1.Image
+
+	files []*os.File
+}
+
+// Track registers the temporary file to be removed by Close
+func (i *TempImage) Track(file *os.File) {
+	if file != nil {
+		i.files = append(i.files, file)
+	}
+}
+
+// Close removes all temporary files of the image
+func (i *TempImage) Close() error {
+	if i == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, file := range i.files {
+		errs = append(errs, RemoveTemp(file))
+	}
+
+	i.files = nil
+	return errors.Join(errs...)
+}
+
+// RemoveTemp closes and removes the temporary file, a file that no longer
+// exists is not an error
+func RemoveTemp(file *os.File) error {
+	_ = file.Close()
+	if err := os.Remove(file.Name()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rebase
+
+import (
+	"reflect"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// mergeConfig applies the changes between the old and the new base image
+// config to the image config, settings that the image overrides are kept
+func mergeConfig(image, oldBase, newBase v1.Config) v1.Config {
+	var result = *image.DeepCopy()
+
+	result.Entrypoint = pick(image.Entrypoint, oldBase.Entrypoint, newBase.Entrypoint)
+	result.Cmd = pick(image.Cmd, oldBase.Cmd, newBase.Cmd)
+	result.Shell = pick(image.Shell, oldBase.Shell, newBase.Shell)
+	result.Healthcheck = pick(image.Healthcheck, oldBase.Healthcheck, newBase.Healthcheck)
+	result.User = pick(image.User, oldBase.User, newBase.User)
+	result.WorkingDir = pick(image.WorkingDir, oldBase.WorkingDir, newBase.WorkingDir)
+	result.StopSignal = pick(image.StopSignal, oldBase.StopSignal, newBase.StopSignal)
+
+	result.Env = mergeEnv(image.Env, oldBase.Env, newBase.Env)
+	result.Labels = mergeMap(image.Labels, oldBase.Labels, newBase.Labels)
+	result.ExposedPorts = mergeMap(image.ExposedPorts, oldBase.ExposedPorts, newBase.ExposedPorts)
+	result.Volumes = mergeMap(image.Volumes, oldBase.Volumes, newBase.Volumes)
+
+	return result
+}
+
+// pick takes the value of the new base, unless the image overrides it
+func pick[T any](image, oldBase, newBase T) T {
+	if reflect.DeepEqual(image, oldBase) {
+		return newBase
+	}
+
+	return image
+}
+
+// mergeMap takes all entries of the new base, unless the image overrides
+// them, plus all entries that only the image has
+func mergeMap[V any](image, oldBase, newBase map[string]V) map[string]V {
+	if image == nil && newBase == nil {
+		return nil
+	}
+
+	var result = map[string]V{}
+	for key, value := range newBase {
+		result[key] = value
+	}
+
+	for key, value := range image {
+		if oldValue, ok := oldBase[key]; ok && reflect.DeepEqual(value, oldValue) {
+			// not overridden by the image, drop it unless the new base has it
+			continue
+		}
+
+		result[key] = value
+	}
+
+	return result
+}
+
+// mergeEnv merges environment variables like mergeMap, but keeps the order
+// of the variables: new base variables first, followed by image variables
+func mergeEnv(image, oldBase, newBase []string) []string {
+	var split = func(env []string) (keys []string, values map[string]string) {
+		values = map[string]string{}
+		for _, entry := range env {
+			key, value, _ := strings.Cut(entry, "=")
+			if _, ok := values[key]; !ok {
+				keys = append(keys, key)
+			}
+
+			values[key] = value
+		}
+
+		return keys, values
+	}
+
+	imageKeys, imageValues := split(image)
+	_, oldBaseValues := split(oldBase)
+	newBaseKeys, newBaseValues := split(newBase)
+
+	var merged = mergeMap(imageValues, oldBaseValues, newBaseValues)
+
+	var result []string
+	var seen = map[string]struct{}{}
+	for _, key := range append(newBaseKeys, imageKeys...) {
+		if _, ok := seen[key]; ok {
+			continue
+		}
+
+		if value, ok := merged[key]; ok {
+			seen[key] = struct{}{}
+			result = append(result, key+"="+value)
+		}
+	}
+
+	return result
+}
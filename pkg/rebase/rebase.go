@@ -0,0 +1,84 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rebase
+
+import (
+	"fmt"
+
+	"github.com/homeport/forklift/pkg/misc"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+)
+
+// Image creates a new image based on the input image, where the layers of
+// the old base image are replaced with the layers of the new base image. The
+// input image has to be based on the old base image, which is verified by
+// comparing the layer diffIDs.
+func Image(input v1.Image, oldBase v1.Image, newBase v1.Image) (v1.Image, error) {
+	boundary, err := misc.BaseBoundary(input, oldBase)
+	if err != nil {
+		return nil, fmt.Errorf("image is not based on the old base image: %w", err)
+	}
+
+	inputConfigFile, err := input.ConfigFile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image config file: %w", err)
+	}
+
+	oldBaseConfigFile, err := oldBase.ConfigFile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read old base image config file: %w", err)
+	}
+
+	newBaseConfigFile, err := newBase.ConfigFile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read new base image config file: %w", err)
+	}
+
+	if inputConfigFile.OS != newBaseConfigFile.OS || inputConfigFile.Architecture != newBaseConfigFile.Architecture {
+		return nil, fmt.Errorf("new base image platform %s/%s does not match image platform %s/%s",
+			newBaseConfigFile.OS, newBaseConfigFile.Architecture,
+			inputConfigFile.OS, inputConfigFile.Architecture,
+		)
+	}
+
+	var configFile = inputConfigFile.DeepCopy()
+	configFile.Config = mergeConfig(inputConfigFile.Config, oldBaseConfigFile.Config, newBaseConfigFile.Config)
+
+	// create a fresh empty image using the merged config file
+	result, err := misc.EmptyImage(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	baseAddenda, err := misc.Addenda(newBase)
+	if err != nil {
+		return nil, err
+	}
+
+	inputAddenda, err := misc.Addenda(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return mutate.Append(result, append(baseAddenda, inputAddenda[boundary:]...)...)
+}
@@ -0,0 +1,130 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rebase_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/homeport/forklift/pkg/rebase"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+func image(base v1.Image, config v1.Config, createdBy ...string) v1.Image {
+	GinkgoHelper()
+
+	var result = base
+	for _, entry := range createdBy {
+		layer, err := random.Layer(512, types.DockerLayer)
+		Expect(err).ToNot(HaveOccurred())
+
+		result, err = mutate.Append(result, mutate.Addendum{
+			Layer:   layer,
+			History: v1.History{CreatedBy: entry},
+		})
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	configFile, err := result.ConfigFile()
+	Expect(err).ToNot(HaveOccurred())
+
+	configFile = configFile.DeepCopy()
+	configFile.Config = config
+
+	result, err = mutate.ConfigFile(result, configFile)
+	Expect(err).ToNot(HaveOccurred())
+
+	return result
+}
+
+func diffIDs(image v1.Image) []v1.Hash {
+	GinkgoHelper()
+
+	configFile, err := image.ConfigFile()
+	Expect(err).ToNot(HaveOccurred())
+
+	return configFile.RootFS.DiffIDs
+}
+
+var _ = Describe("Rebase", func() {
+	var oldBase, newBase, app v1.Image
+
+	BeforeEach(func() {
+		oldBase = image(empty.Image, v1.Config{
+			Env:    []string{"PATH=/bin", "TZ=UTC", "VERSION=1"},
+			Cmd:    []string{"/bin/sh"},
+			User:   "root",
+			Labels: map[string]string{"vendor": "base", "version": "1"},
+		}, "ADD rootfs.tar /")
+
+		newBase = image(empty.Image, v1.Config{
+			Env:    []string{"PATH=/usr/bin:/bin", "TZ=UTC", "VERSION=2"},
+			Cmd:    []string{"/bin/bash"},
+			User:   "nobody",
+			Labels: map[string]string{"vendor": "base", "version": "2"},
+		}, "ADD rootfs.tar /", "RUN apply-patches")
+
+		app = image(oldBase, v1.Config{
+			Env:        []string{"PATH=/bin", "TZ=Europe/Berlin", "VERSION=1", "APP=true"},
+			Entrypoint: []string{"/app"},
+			Cmd:        []string{"/bin/sh"},
+			User:       "root",
+			Labels:     map[string]string{"vendor": "app", "version": "1"},
+		}, "COPY app /app")
+	})
+
+	It("should swap the base image layers", func() {
+		result, err := rebase.Image(app, oldBase, newBase)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(diffIDs(result)).To(Equal(append(diffIDs(newBase), diffIDs(app)[1:]...)))
+
+		configFile, err := result.ConfigFile()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(configFile.History).To(HaveLen(3))
+		Expect(configFile.History[1].CreatedBy).To(Equal("RUN apply-patches"))
+		Expect(configFile.History[2].CreatedBy).To(Equal("COPY app /app"))
+	})
+
+	It("should keep the image settings and take the updated base image defaults", func() {
+		result, err := rebase.Image(app, oldBase, newBase)
+		Expect(err).ToNot(HaveOccurred())
+
+		configFile, err := result.ConfigFile()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(configFile.Config.Env).To(Equal([]string{"PATH=/usr/bin:/bin", "TZ=Europe/Berlin", "VERSION=2", "APP=true"}))
+		Expect(configFile.Config.Entrypoint).To(Equal([]string{"/app"}))
+		Expect(configFile.Config.Cmd).To(Equal([]string{"/bin/bash"}))
+		Expect(configFile.Config.User).To(Equal("nobody"))
+		Expect(configFile.Config.Labels).To(Equal(map[string]string{"vendor": "app", "version": "2"}))
+	})
+
+	It("should fail if the image is not based on the old base image", func() {
+		_, err := rebase.Image(app, newBase, oldBase)
+		Expect(err).To(MatchError(ContainSubstring("image is not based on the old base image")))
+	})
+})
@@ -0,0 +1,124 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package registries
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Settings are the connection settings for a registry
+type Settings struct {
+	// Insecure allows plain HTTP and skips the TLS certificate verification
+	Insecure bool `yaml:"insecure,omitempty"`
+
+	// CACerts are PEM files with additional trusted CA certificates
+	CACerts []string `yaml:"ca_certs,omitempty"`
+
+	// ClientCert and ClientKey are the PEM files for TLS client certificate
+	// authentication
+	ClientCert string `yaml:"client_cert,omitempty"`
+	ClientKey  string `yaml:"client_key,omitempty"`
+
+	// Proxy is the URL of the proxy to use, by default the proxy is taken
+	// from the HTTPS_PROXY, HTTP_PROXY, and NO_PROXY environment variables
+	Proxy string `yaml:"proxy,omitempty"`
+}
+
+// Config is the registry configuration, with default settings for all
+// registries, settings per registry host, and mirror rules
+type Config struct {
+	Default    Settings            `yaml:"default,omitempty"`
+	Registries map[string]Settings `yaml:"registries,omitempty"`
+	Mirrors    Mirrors             `yaml:"mirrors,omitempty"`
+}
+
+// DefaultConfigFile returns the location of the user's registry config file
+func DefaultConfigFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "forklift", "registries.yaml")
+}
+
+// LoadConfig reads the registry configuration from a YAML file
+func LoadConfig(filename string) (*Config, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse registry config file %s: %w", filename, err)
+	}
+
+	if err := config.Mirrors.validate(); err != nil {
+		return nil, fmt.Errorf("invalid registry config file %s: %w", filename, err)
+	}
+
+	return &config, nil
+}
+
+// Settings returns the settings for the registry host, which are the
+// default settings combined with the settings of the host, a host can be
+// configured with or without port
+func (c *Config) Settings(host string) Settings {
+	var result = c.Default
+	result.CACerts = append([]string{}, c.Default.CACerts...)
+
+	settings, ok := c.Registries[host]
+	if !ok {
+		if hostname, _, err := net.SplitHostPort(host); err == nil {
+			settings = c.Registries[hostname]
+		}
+	}
+
+	result.Insecure = result.Insecure || settings.Insecure
+	result.CACerts = append(result.CACerts, settings.CACerts...)
+
+	if settings.ClientCert != "" {
+		result.ClientCert, result.ClientKey = settings.ClientCert, settings.ClientKey
+	}
+
+	if settings.Proxy != "" {
+		result.Proxy = settings.Proxy
+	}
+
+	return result
+}
+
+// SetInsecure marks the registry host as insecure
+func (c *Config) SetInsecure(host string) {
+	if c.Registries == nil {
+		c.Registries = map[string]Settings{}
+	}
+
+	var settings = c.Registries[host]
+	settings.Insecure = true
+	c.Registries[host] = settings
+}
@@ -0,0 +1,82 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package registries_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/homeport/forklift/pkg/registries"
+)
+
+var _ = Describe("Config", func() {
+	It("should combine default and registry settings", func() {
+		var filename = filepath.Join(GinkgoT().TempDir(), "registries.yaml")
+		Expect(os.WriteFile(filename, []byte(`---
+default:
+  ca_certs: [/etc/ssl/corp.pem]
+  proxy: http://proxy:3128
+
+registries:
+  registry.internal:
+    insecure: true
+
+  harbor.example.com:5000:
+    ca_certs: [/etc/ssl/harbor.pem]
+    client_cert: /etc/ssl/client.pem
+    client_key: /etc/ssl/client-key.pem
+    proxy: http://other:3128
+`), 0644)).To(Succeed())
+
+		config, err := registries.LoadConfig(filename)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(config.Settings("ghcr.io")).To(Equal(registries.Settings{
+			CACerts: []string{"/etc/ssl/corp.pem"},
+			Proxy:   "http://proxy:3128",
+		}))
+
+		Expect(config.Settings("registry.internal:5000")).To(Equal(registries.Settings{
+			Insecure: true,
+			CACerts:  []string{"/etc/ssl/corp.pem"},
+			Proxy:    "http://proxy:3128",
+		}))
+
+		Expect(config.Settings("harbor.example.com:5000")).To(Equal(registries.Settings{
+			CACerts:    []string{"/etc/ssl/corp.pem", "/etc/ssl/harbor.pem"},
+			ClientCert: "/etc/ssl/client.pem",
+			ClientKey:  "/etc/ssl/client-key.pem",
+			Proxy:      "http://other:3128",
+		}))
+
+		Expect(config.Settings("harbor.example.com").ClientCert).To(BeEmpty())
+	})
+
+	It("should mark registries as insecure", func() {
+		var config registries.Config
+		config.SetInsecure("localhost:5000")
+		Expect(config.Settings("localhost:5000").Insecure).To(BeTrue())
+		Expect(config.Settings("localhost:5001").Insecure).To(BeFalse())
+	})
+})
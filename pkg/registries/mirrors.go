@@ -0,0 +1,153 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package registries
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+// Mirror is a rewrite rule for image references: From is a repository, or
+// a registry or repository prefix ending with /*, and To are the
+// replacements in the same form, which are tried in order
+type Mirror struct {
+	From string   `yaml:"from"`
+	To   []string `yaml:"to"`
+
+	// NoFallback prevents using the original repository when none of the
+	// replacements can serve the image
+	NoFallback bool `yaml:"no_fallback,omitempty"`
+}
+
+// Mirrors is a list of rewrite rules, the first matching rule applies
+type Mirrors []Mirror
+
+// Endpoints returns the repositories to try in order for the repository,
+// which is the repository itself if no rule matches
+func (m Mirrors) Endpoints(repo name.Repository) ([]name.Repository, error) {
+	for _, mirror := range m {
+		rest, ok, err := mirror.match(repo)
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok {
+			continue
+		}
+
+		var result []name.Repository
+		for _, to := range mirror.To {
+			endpoint, err := mirror.replace(to, rest)
+			if err != nil {
+				return nil, err
+			}
+
+			result = append(result, endpoint)
+		}
+
+		if !mirror.NoFallback {
+			result = append(result, repo)
+		}
+
+		return result, nil
+	}
+
+	return []name.Repository{repo}, nil
+}
+
+// validate checks that all patterns of the rules can be used
+func (m Mirrors) validate() error {
+	for _, mirror := range m {
+		if len(mirror.To) == 0 {
+			return fmt.Errorf("mirror rule for %s has no replacement", mirror.From)
+		}
+
+		if _, err := prefix(mirror.From); err != nil {
+			return err
+		}
+
+		for _, to := range mirror.To {
+			if _, err := mirror.replace(to, "foo"); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// match reports whether the rule applies to the repository, and returns the
+// remaining repository path for prefix rules
+func (m Mirror) match(repo name.Repository) (string, bool, error) {
+	from, err := prefix(m.From)
+	if err != nil {
+		return "", false, err
+	}
+
+	if !strings.HasSuffix(m.From, "/*") {
+		return "", from == repo.Name(), nil
+	}
+
+	rest, ok := strings.CutPrefix(repo.Name(), from+"/")
+	return rest, ok, nil
+}
+
+// replace creates the replacement repository for the remaining path
+func (m Mirror) replace(to string, rest string) (name.Repository, error) {
+	switch {
+	case strings.HasSuffix(m.From, "/*") && strings.HasSuffix(to, "/*"):
+		return name.NewRepository(strings.TrimSuffix(to, "/*") + "/" + rest)
+
+	case !strings.HasSuffix(m.From, "/*") && !strings.HasSuffix(to, "/*"):
+		return name.NewRepository(to)
+
+	default:
+		return name.Repository{}, fmt.Errorf("mirror rule %s -> %s must use /* on both sides or on neither", m.From, to)
+	}
+}
+
+// prefix returns the normalized repository or prefix of the pattern, for
+// example index.docker.io for docker.io/*
+func prefix(pattern string) (string, error) {
+	trimmed, wildcard := strings.CutSuffix(pattern, "/*")
+	if !wildcard {
+		repo, err := name.NewRepository(pattern)
+		if err != nil {
+			return "", fmt.Errorf("invalid mirror pattern %s: %w", pattern, err)
+		}
+
+		return repo.Name(), nil
+	}
+
+	host, path, _ := strings.Cut(trimmed, "/")
+	registry, err := name.NewRegistry(host)
+	if err != nil {
+		return "", fmt.Errorf("invalid mirror pattern %s: %w", pattern, err)
+	}
+
+	if path == "" {
+		return registry.Name(), nil
+	}
+
+	return registry.Name() + "/" + path, nil
+}
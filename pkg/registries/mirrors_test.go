@@ -0,0 +1,87 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package registries_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/homeport/forklift/pkg/registries"
+
+	"github.com/google/go-containerregistry/pkg/name"
+
+	"github.com/homeport/forklift/internal/imagetest"
+)
+
+var _ = Describe("Mirrors", func() {
+	var endpoints = func(mirrors registries.Mirrors, repo string) []string {
+		GinkgoHelper()
+
+		var result []string
+		for _, endpoint := range imagetest.Must(mirrors.Endpoints(imagetest.Must(name.NewRepository(repo)))) {
+			result = append(result, endpoint.Name())
+		}
+
+		return result
+	}
+
+	It("should rewrite registry prefixes and fall back to the original", func() {
+		var mirrors = registries.Mirrors{
+			{From: "docker.io/*", To: []string{"mirror.corp/dockerhub/*", "backup.corp/dockerhub/*"}},
+		}
+
+		Expect(endpoints(mirrors, "ubuntu")).To(Equal([]string{
+			"mirror.corp/dockerhub/library/ubuntu",
+			"backup.corp/dockerhub/library/ubuntu",
+			"index.docker.io/library/ubuntu",
+		}))
+
+		Expect(endpoints(mirrors, "ghcr.io/homeport/forklift")).To(Equal([]string{
+			"ghcr.io/homeport/forklift",
+		}))
+	})
+
+	It("should use the first matching rule", func() {
+		var mirrors = registries.Mirrors{
+			{From: "ghcr.io/homeport/forklift", To: []string{"mirror.corp/forklift"}, NoFallback: true},
+			{From: "ghcr.io/homeport/*", To: []string{"mirror.corp/homeport/*"}},
+		}
+
+		Expect(endpoints(mirrors, "ghcr.io/homeport/forklift")).To(Equal([]string{"mirror.corp/forklift"}))
+		Expect(endpoints(mirrors, "ghcr.io/homeport/dyff")).To(Equal([]string{"mirror.corp/homeport/dyff", "ghcr.io/homeport/dyff"}))
+		Expect(endpoints(mirrors, "ghcr.io/homeportx/dyff")).To(Equal([]string{"ghcr.io/homeportx/dyff"}))
+	})
+
+	It("should reject inconsistent rules in the config file", func() {
+		var filename = filepath.Join(GinkgoT().TempDir(), "registries.yaml")
+		Expect(os.WriteFile(filename, []byte(`---
+mirrors:
+- from: docker.io/*
+  to: [mirror.corp/dockerhub]
+`), 0644)).To(Succeed())
+
+		_, err := registries.LoadConfig(filename)
+		Expect(err).To(MatchError(ContainSubstring("must use /* on both sides or on neither")))
+	})
+})
@@ -0,0 +1,116 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package registries
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimitError is returned when a registry keeps rejecting requests
+// because its rate limit is exceeded
+type RateLimitError struct {
+	Host string
+
+	// RetryAfter is the wait time requested by the registry, if any
+	RetryAfter time.Duration
+
+	// Limit, Remaining, and Window are taken from the RateLimit-Limit and
+	// RateLimit-Remaining headers (Docker Hub), if present
+	Limit     int
+	Remaining int
+	Window    time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	var msg = fmt.Sprintf("rate limit of %s exceeded", e.Host)
+	if e.Limit > 0 {
+		msg += fmt.Sprintf(" (%d of %d requests left per %s)", e.Remaining, e.Limit, e.Window)
+	}
+
+	if e.RetryAfter > 0 {
+		msg += fmt.Sprintf(", retry in %s", e.RetryAfter.Round(time.Second))
+	}
+
+	if isDockerHub(e.Host) {
+		msg += ", anonymous Docker Hub pulls have a lower limit than authenticated ones (see forklift login)"
+	}
+
+	return msg
+}
+
+func rateLimitError(req *http.Request, resp *http.Response, retryAfter time.Duration) *RateLimitError {
+	var err = RateLimitError{Host: req.URL.Host, RetryAfter: retryAfter}
+	if limit, window, ok := rateLimit(resp.Header.Get("RateLimit-Limit")); ok {
+		err.Limit, err.Window = limit, window
+		err.Remaining, _, _ = rateLimit(resp.Header.Get("RateLimit-Remaining"))
+	}
+
+	return &err
+}
+
+// rateLimit parses rate limit headers like 100;w=21600, which is a limit of
+// 100 requests in a window of 21600 seconds
+func rateLimit(value string) (int, time.Duration, bool) {
+	count, params, _ := strings.Cut(value, ";")
+	limit, err := strconv.Atoi(strings.TrimSpace(count))
+	if err != nil {
+		return 0, 0, false
+	}
+
+	var window time.Duration
+	for _, param := range strings.Split(params, ";") {
+		if seconds, ok := strings.CutPrefix(strings.TrimSpace(param), "w="); ok {
+			if n, err := strconv.Atoi(seconds); err == nil {
+				window = time.Duration(n) * time.Second
+			}
+		}
+	}
+
+	return limit, window, true
+}
+
+// logRateLimit logs the remaining requests reported by the registry
+func (t *Transport) logRateLimit(resp *http.Response) {
+	if resp == nil {
+		return
+	}
+
+	limit, window, ok := rateLimit(resp.Header.Get("RateLimit-Limit"))
+	if !ok {
+		return
+	}
+
+	remaining, _, _ := rateLimit(resp.Header.Get("RateLimit-Remaining"))
+	t.logf("%s: %d of %d requests left per %s", resp.Request.URL.Host, remaining, limit, window)
+}
+
+func isDockerHub(host string) bool {
+	switch host {
+	case "registry-1.docker.io", "index.docker.io", "docker.io":
+		return true
+	}
+
+	return false
+}
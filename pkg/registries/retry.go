@@ -0,0 +1,192 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package registries
+
+import (
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Retry configures how failed registry requests are retried
+type Retry struct {
+	// Retries is the number of retries after the first attempt
+	Retries int
+
+	// Backoff is the wait time before the first retry, it doubles with every
+	// further retry, with a random jitter of up to 20 percent
+	Backoff time.Duration
+
+	// MaxWait is the longest wait time before a retry, a registry asking to
+	// wait longer (Retry-After) fails the request right away
+	MaxWait time.Duration
+}
+
+// DefaultRetry retries three times, after one, two, and four seconds
+var DefaultRetry = Retry{Retries: 3, Backoff: time.Second, MaxWait: time.Minute}
+
+// retryStatusCodes are the response codes of requests that are retried
+var retryStatusCodes = []int{
+	http.StatusRequestTimeout,
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// idempotentMethods are the methods of requests that are retried, a POST
+// request is never retried, since it might have started an upload already
+var idempotentMethods = []string{http.MethodGet, http.MethodHead, http.MethodPut}
+
+// roundTripWithRetry sends the request using fn and retries it if it fails
+// with a temporary error, only idempotent requests are retried, and only if
+// their body can be sent again
+func (t *Transport) roundTripWithRetry(req *http.Request, fn func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.Body != nil && req.Body != http.NoBody {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		resp, err := fn(req)
+		if !retryable(req, resp, err) {
+			return resp, err
+		}
+
+		var wait, limited = t.wait(resp, attempt)
+		var last = attempt >= t.retry.Retries || wait > t.retry.MaxWait || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil)
+		if last {
+			if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+				_ = resp.Body.Close()
+				return nil, rateLimitError(req, resp, wait)
+			}
+
+			return resp, err
+		}
+
+		var reason string
+		if err != nil {
+			reason = err.Error()
+
+		} else {
+			reason = resp.Status
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+			_ = resp.Body.Close()
+		}
+
+		t.logf("%s %s failed (%s), retrying in %s (%d of %d)", req.Method, req.URL.Redacted(), reason, wait.Round(time.Millisecond), attempt+1, t.retry.Retries)
+		if limited {
+			t.logf("%s asked to retry after %s", req.URL.Host, wait)
+		}
+
+		var timer = time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+
+		case <-timer.C:
+		}
+	}
+}
+
+// wait returns the wait time before the next attempt, which is given by
+// the registry (Retry-After), or the exponential backoff
+func (t *Transport) wait(resp *http.Response, attempt int) (time.Duration, bool) {
+	if resp != nil {
+		if wait, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			return wait, true
+		}
+	}
+
+	var wait = t.retry.Backoff << attempt
+	if wait <= 0 || wait > t.retry.MaxWait {
+		wait = t.retry.MaxWait
+	}
+
+	return wait - time.Duration(rand.Float64()*0.2*float64(wait)), false
+}
+
+// retryable reports whether the request failed in a way that a later
+// attempt may succeed
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+
+	var method = req.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	if !slices.Contains(idempotentMethods, method) {
+		return false
+	}
+
+	if err == nil {
+		return slices.Contains(retryStatusCodes, resp.StatusCode)
+	}
+
+	var netErr net.Error
+	switch {
+	case errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, syscall.ECONNREFUSED),
+		errors.Is(err, syscall.EPIPE),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return true
+
+	default:
+		var dnsErr *net.DNSError
+		return errors.As(err, &dnsErr) && dnsErr.IsTemporary
+	}
+}
+
+// retryAfter parses the Retry-After header, which is either a number of
+// seconds or a date
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+
+	return 0, false
+}
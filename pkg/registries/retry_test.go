@@ -0,0 +1,153 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package registries_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/homeport/forklift/internal/imagetest"
+	"github.com/homeport/forklift/pkg/registries"
+)
+
+var _ = Describe("Retry", func() {
+	var fast = registries.Retry{Retries: 3, Backoff: time.Millisecond, MaxWait: time.Second}
+
+	var serve = func(handler func(attempt int32, w http.ResponseWriter, r *http.Request)) (*httptest.Server, *atomic.Int32) {
+		GinkgoHelper()
+
+		var attempts atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handler(attempts.Add(1), w, r)
+		}))
+
+		DeferCleanup(server.Close)
+		return server, &attempts
+	}
+
+	var client = func(opts ...registries.Option) *http.Client {
+		GinkgoHelper()
+
+		return &http.Client{Transport: imagetest.Must(registries.NewTransport(&registries.Config{}, opts...))}
+	}
+
+	It("should retry temporary failures", func() {
+		server, attempts := serve(func(attempt int32, w http.ResponseWriter, _ *http.Request) {
+			if attempt < 3 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+
+			w.WriteHeader(http.StatusOK)
+		})
+
+		resp := imagetest.Must(client(registries.WithRetry(fast)).Get(server.URL))
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(attempts.Load()).To(BeEquivalentTo(3))
+	})
+
+	It("should send the request body again", func() {
+		var bodies []string
+		server, _ := serve(func(attempt int32, w http.ResponseWriter, r *http.Request) {
+			bodies = append(bodies, string(imagetest.Must(io.ReadAll(r.Body))))
+			if attempt < 2 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+
+			w.WriteHeader(http.StatusCreated)
+		})
+
+		req := imagetest.Must(http.NewRequest(http.MethodPut, server.URL, bytes.NewReader([]byte("data"))))
+		resp := imagetest.Must(client(registries.WithRetry(fast)).Do(req))
+		Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+		Expect(bodies).To(Equal([]string{"data", "data"}))
+	})
+
+	It("should not retry POST requests", func() {
+		server, attempts := serve(func(_ int32, w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+
+		resp := imagetest.Must(client(registries.WithRetry(fast)).Post(server.URL, "text/plain", bytes.NewReader([]byte("data"))))
+		Expect(resp.StatusCode).To(Equal(http.StatusServiceUnavailable))
+		Expect(attempts.Load()).To(BeEquivalentTo(1))
+	})
+
+	It("should not retry other failures", func() {
+		server, attempts := serve(func(_ int32, w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		})
+
+		resp := imagetest.Must(client(registries.WithRetry(fast)).Get(server.URL))
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		Expect(attempts.Load()).To(BeEquivalentTo(1))
+	})
+
+	It("should report rate limits with the details from the registry", func() {
+		server, attempts := serve(func(_ int32, w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Retry-After", "0")
+			w.Header().Set("RateLimit-Limit", "100;w=21600")
+			w.Header().Set("RateLimit-Remaining", "0;w=21600")
+			w.WriteHeader(http.StatusTooManyRequests)
+		})
+
+		_, err := client(registries.WithRetry(fast)).Get(server.URL)
+		Expect(attempts.Load()).To(BeEquivalentTo(4))
+
+		var rateLimitErr *registries.RateLimitError
+		Expect(errors.As(err, &rateLimitErr)).To(BeTrue())
+		Expect(rateLimitErr.Limit).To(Equal(100))
+		Expect(rateLimitErr.Remaining).To(Equal(0))
+		Expect(rateLimitErr.Window).To(Equal(6 * time.Hour))
+		Expect(err).To(MatchError(ContainSubstring("(0 of 100 requests left per 6h0m0s)")))
+	})
+
+	It("should not wait longer than the maximum wait time", func() {
+		server, attempts := serve(func(_ int32, w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+		})
+
+		_, err := client(registries.WithRetry(fast)).Get(server.URL)
+		Expect(err).To(MatchError(ContainSubstring("retry in 1h0m0s")))
+		Expect(attempts.Load()).To(BeEquivalentTo(1))
+	})
+
+	It("should limit the time to wait for a response", func() {
+		server, attempts := serve(func(_ int32, w http.ResponseWriter, _ *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+			w.WriteHeader(http.StatusOK)
+		})
+
+		_, err := client(registries.WithRetry(registries.Retry{Retries: 1, Backoff: time.Millisecond, MaxWait: time.Second}), registries.WithRequestTimeout(20*time.Millisecond)).Get(server.URL)
+		Expect(err).To(MatchError(ContainSubstring("timeout awaiting response headers")))
+		Expect(attempts.Load()).To(BeEquivalentTo(2))
+	})
+})
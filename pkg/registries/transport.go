@@ -0,0 +1,232 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package registries
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// Transport is an HTTP transport that uses the connection settings of the
+// registry host of each request, and retries failed requests
+type Transport struct {
+	config         *Config
+	retry          Retry
+	requestTimeout time.Duration
+	log            *log.Logger
+
+	mu         sync.Mutex
+	transports map[string]http.RoundTripper
+	plainHTTP  map[string]bool
+}
+
+// Option configures the transport
+type Option func(*Transport)
+
+// WithRetry configures how failed requests are retried, DefaultRetry is
+// used otherwise
+func WithRetry(retry Retry) Option {
+	return func(t *Transport) { t.retry = retry }
+}
+
+// WithRequestTimeout limits how long to wait for the response of a registry
+// to a request, it does not limit the time to read the response body
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(t *Transport) { t.requestTimeout = timeout }
+}
+
+// WithLog logs retries and the rate limit status reported by registries
+func WithLog(log *log.Logger) Option {
+	return func(t *Transport) { t.log = log }
+}
+
+// NewTransport creates a transport for the configuration, it fails if any
+// of the configured certificate files cannot be used
+func NewTransport(config *Config, opts ...Option) (*Transport, error) {
+	var t = &Transport{
+		config:     config,
+		retry:      DefaultRetry,
+		transports: map[string]http.RoundTripper{},
+		plainHTTP:  map[string]bool{},
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	// set up all configured hosts upfront to report broken settings early
+	for host := range config.Registries {
+		if _, err := t.transport(host); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := t.transport(""); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.roundTripWithRetry(req, t.roundTrip)
+	t.logRateLimit(resp)
+	return resp, err
+}
+
+// roundTrip sends the request using the transport of the host, requests to
+// insecure hosts that turn out to serve plain HTTP are repeated using HTTP
+func (t *Transport) roundTrip(req *http.Request) (*http.Response, error) {
+	var host = req.URL.Host
+	rt, err := t.transport(host)
+	if err != nil {
+		return nil, err
+	}
+
+	if !t.config.Settings(host).Insecure || req.URL.Scheme != "https" {
+		return rt.RoundTrip(req)
+	}
+
+	t.mu.Lock()
+	var plainHTTP = t.plainHTTP[host]
+	t.mu.Unlock()
+
+	if !plainHTTP {
+		resp, err := rt.RoundTrip(req)
+		if !isPlainHTTPResponse(err) || (req.Body != nil && req.GetBody == nil) {
+			return resp, err
+		}
+
+		t.mu.Lock()
+		t.plainHTTP[host] = true
+		t.mu.Unlock()
+	}
+
+	var plain = req.Clone(req.Context())
+	plain.URL.Scheme = "http"
+	if req.GetBody != nil {
+		if plain.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+
+	return rt.RoundTrip(plain)
+}
+
+// transport returns the transport for the host, creating it on first use
+func (t *Transport) transport(host string) (http.RoundTripper, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if rt, ok := t.transports[host]; ok {
+		return rt, nil
+	}
+
+	rt, err := newTransport(host, t.config.Settings(host))
+	if err != nil {
+		return nil, err
+	}
+
+	rt.ResponseHeaderTimeout = t.requestTimeout
+	t.transports[host] = rt
+	return rt, nil
+}
+
+func newTransport(host string, settings Settings) (*http.Transport, error) {
+	var rt = remote.DefaultTransport.(*http.Transport).Clone()
+	if rt.TLSClientConfig == nil {
+		rt.TLSClientConfig = &tls.Config{}
+	}
+
+	rt.TLSClientConfig.InsecureSkipVerify = settings.Insecure
+
+	if len(settings.CACerts) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		for _, filename := range settings.CACerts {
+			data, err := os.ReadFile(filename)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CA certificate for %s: %w", describe(host), err)
+			}
+
+			if !pool.AppendCertsFromPEM(data) {
+				return nil, fmt.Errorf("no PEM encoded certificates in %s", filename)
+			}
+		}
+
+		rt.TLSClientConfig.RootCAs = pool
+	}
+
+	if settings.ClientCert != "" || settings.ClientKey != "" {
+		cert, err := tls.LoadX509KeyPair(settings.ClientCert, settings.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate for %s: %w", describe(host), err)
+		}
+
+		rt.TLSClientConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if settings.Proxy != "" {
+		proxy, err := url.Parse(settings.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy for %s: %w", describe(host), err)
+		}
+
+		rt.Proxy = http.ProxyURL(proxy)
+	}
+
+	return rt, nil
+}
+
+// isPlainHTTPResponse reports whether the error is the result of a server
+// responding with plain HTTP to a TLS handshake (same check as http.Client)
+func isPlainHTTPResponse(err error) bool {
+	var recordHeaderErr tls.RecordHeaderError
+	return errors.As(err, &recordHeaderErr) && string(recordHeaderErr.RecordHeader[:]) == "HTTP/"
+}
+
+func (t *Transport) logf(format string, a ...any) {
+	if t.log != nil {
+		t.log.Printf(format, a...)
+	}
+}
+
+func describe(host string) string {
+	if host == "" {
+		return "all registries"
+	}
+
+	return host
+}
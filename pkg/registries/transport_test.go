@@ -0,0 +1,124 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package registries_test
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/homeport/forklift/internal/imagetest"
+	"github.com/homeport/forklift/pkg/registries"
+)
+
+var _ = Describe("Transport", func() {
+	var ok = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	get := func(config *registries.Config, url string) (*http.Response, error) {
+		GinkgoHelper()
+
+		transport, err := registries.NewTransport(config)
+		Expect(err).ToNot(HaveOccurred())
+
+		return (&http.Client{Transport: transport}).Get(url)
+	}
+
+	Context("registry with self-signed certificate", func() {
+		var server *httptest.Server
+		var host string
+
+		BeforeEach(func() {
+			server = httptest.NewTLSServer(ok)
+			DeferCleanup(server.Close)
+			host = strings.TrimPrefix(server.URL, "https://")
+		})
+
+		It("should fail without the CA certificate", func() {
+			_, err := get(&registries.Config{}, server.URL+"/v2/")
+			Expect(err).To(MatchError(ContainSubstring("certificate")))
+		})
+
+		It("should trust the given CA certificate", func() {
+			var filename = filepath.Join(GinkgoT().TempDir(), "ca.pem")
+			Expect(os.WriteFile(filename, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0644)).To(Succeed())
+
+			resp := imagetest.Must(get(&registries.Config{Registries: map[string]registries.Settings{host: {CACerts: []string{filename}}}}, server.URL+"/v2/"))
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		})
+
+		It("should skip the verification for insecure registries", func() {
+			var config registries.Config
+			config.SetInsecure(host)
+
+			resp := imagetest.Must(get(&config, server.URL+"/v2/"))
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		})
+	})
+
+	Context("registry using plain HTTP", func() {
+		var host string
+
+		BeforeEach(func() {
+			server := httptest.NewServer(ok)
+			DeferCleanup(server.Close)
+			host = strings.TrimPrefix(server.URL, "http://")
+		})
+
+		It("should use plain HTTP for insecure registries", func() {
+			var config registries.Config
+			config.SetInsecure(host)
+
+			resp := imagetest.Must(get(&config, "https://"+host+"/v2/"))
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		})
+
+		It("should not use plain HTTP for other registries", func() {
+			_, err := get(&registries.Config{}, "https://"+host+"/v2/")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	It("should use the configured proxy", func() {
+		var proxied []string
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			proxied = append(proxied, r.URL.String())
+			w.WriteHeader(http.StatusOK)
+		}))
+		DeferCleanup(proxy.Close)
+
+		resp := imagetest.Must(get(&registries.Config{Default: registries.Settings{Proxy: proxy.URL}}, "http://registry.invalid/v2/"))
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(proxied).To(ConsistOf("http://registry.invalid/v2/"))
+	})
+
+	It("should report unusable certificate files", func() {
+		_, err := registries.NewTransport(&registries.Config{Default: registries.Settings{CACerts: []string{"/does/not/exist.pem"}}})
+		Expect(err).To(MatchError(ContainSubstring("failed to read CA certificate for all registries")))
+	})
+})
@@ -0,0 +1,276 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package remove
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/homeport/forklift/pkg/misc"
+	forklifttar "github.com/homeport/forklift/pkg/tar"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+)
+
+// Change describes a layer that was rewritten to remove paths
+type Change struct {
+	// Index of the history entry (see misc.Layers)
+	Index int
+
+	// CreatedBy of the original history entry
+	CreatedBy string
+
+	// Removed lists the names of the removed entries
+	Removed []string
+}
+
+// Option configures how paths are removed
+type Option func(*options)
+
+type options struct {
+	tempDir          string
+	compressionLevel int
+}
+
+// WithTempDir creates the temporary tarballs in the given directory instead
+// of the default directory for temporary files
+func WithTempDir(dir string) Option {
+	return func(o *options) { o.tempDir = dir }
+}
+
+// WithCompressionLevel sets the gzip compression level of new layers, the
+// default is gzip.DefaultCompression
+func WithCompressionLevel(level int) Option {
+	return func(o *options) { o.compressionLevel = level }
+}
+
+func newOptions(opts []Option) options {
+	var o = options{compressionLevel: gzip.DefaultCompression}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// Whiteout appends a layer with whiteout entries for all files and
+// directories of the image that match any of the patterns (see tar.Match),
+// the existing layers are kept as-is. It returns the removed paths. The
+// result has to be closed after it was written to remove temporary files.
+func Whiteout(input v1.Image, patterns []string, opts ...Option) (*misc.TempImage, []string, error) {
+	if err := forklifttar.ValidatePatterns(patterns); err != nil {
+		return nil, nil, err
+	}
+
+	layers, err := input.Layers()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	files, err := forklifttar.Files(layers...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// use the top-most matching path, which might be a parent directory
+	// that has no entry of its own in the layers
+	var matches = map[string]struct{}{}
+	for name := range files {
+		var elems = strings.Split(name, "/")
+		for i := 1; i <= len(elems); i++ {
+			var prefix = strings.Join(elems[:i], "/")
+			matched, err := forklifttar.MatchAny(patterns, prefix)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if matched {
+				matches[prefix] = struct{}{}
+				break
+			}
+		}
+	}
+
+	// only the top-most matching path is required
+	var removed []string
+	for name := range matches {
+		if !hasParentIn(name, matches) {
+			removed = append(removed, name)
+		}
+	}
+
+	if len(removed) == 0 {
+		return &misc.TempImage{Image: input}, nil, nil
+	}
+
+	slices.Sort(removed)
+
+	var o = newOptions(opts)
+	tmpball, err := forklifttar.Whiteouts(removed, forklifttar.WithTempDir(o.tempDir))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var temp = &misc.TempImage{}
+	temp.Track(tmpball)
+
+	layer, err := tarball.LayerFromFile(tmpball.Name(), tarball.WithCompressionLevel(o.compressionLevel))
+	if err != nil {
+		_ = temp.Close()
+		return nil, nil, err
+	}
+
+	result, err := mutate.Append(input, mutate.Addendum{
+		Layer: layer,
+		History: v1.History{
+			Author:    "forklift",
+			Comment:   fmt.Sprintf("removed %d paths", len(removed)),
+			Created:   v1.Time{Time: time.Now().UTC()},
+			CreatedBy: "RUN rm -rf /" + strings.Join(removed, " /"),
+		},
+	})
+
+	if err != nil {
+		_ = temp.Close()
+		return nil, nil, err
+	}
+
+	temp.Image = result
+	return temp, removed, nil
+}
+
+// Purge rewrites all layers that contain entries matching any of the
+// patterns (see tar.Match) without those entries, so that the content is
+// no longer part of any layer blob. Hardlinks to a removed entry are removed
+// as well. It returns the layers that changed. The result has to be closed
+// after it was written to remove temporary files.
+func Purge(input v1.Image, patterns []string, opts ...Option) (*misc.TempImage, []Change, error) {
+	if err := forklifttar.ValidatePatterns(patterns); err != nil {
+		return nil, nil, err
+	}
+
+	var o = newOptions(opts)
+	configFile, err := input.ConfigFile()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read image config file: %w", err)
+	}
+
+	layers, err := misc.Layers(input)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, err := misc.EmptyImage(configFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var keep = func(header *tar.Header) (bool, error) {
+		matched, err := forklifttar.MatchAny(patterns, header.Name)
+		if err != nil || matched {
+			return false, err
+		}
+
+		// a hardlink would be left dangling without its target
+		if header.Typeflag == tar.TypeLink {
+			matched, err = forklifttar.MatchAny(patterns, header.Linkname)
+		}
+
+		return !matched, err
+	}
+
+	var temp = &misc.TempImage{}
+	var fail = func(err error) (*misc.TempImage, []Change, error) {
+		_ = temp.Close()
+		return nil, nil, err
+	}
+
+	var changes []Change
+	var addenda = make([]mutate.Addendum, 0, len(layers))
+	for i, layer := range layers {
+		var addendum = mutate.Addendum{Layer: layer.Layer, History: *layer.History}
+		if layer.Layer == nil {
+			addenda = append(addenda, addendum)
+			continue
+		}
+
+		tmpball, removed, err := forklifttar.Filter(layer.Layer, keep, forklifttar.WithTempDir(o.tempDir))
+		if err != nil {
+			return fail(fmt.Errorf("failed to filter layer %d: %w", i, err))
+		}
+
+		if len(removed) == 0 {
+			// layer is unchanged, the original one is used
+			if err := misc.RemoveTemp(tmpball); err != nil {
+				return fail(err)
+			}
+
+		} else {
+			temp.Track(tmpball)
+			addendum.Layer, err = tarball.LayerFromFile(tmpball.Name(), tarball.WithCompressionLevel(o.compressionLevel))
+			if err != nil {
+				return fail(err)
+			}
+
+			var comment = fmt.Sprintf("forklift removed %d paths", len(removed))
+			if addendum.History.Comment != "" {
+				comment = addendum.History.Comment + ", " + comment
+			}
+
+			addendum.History.Comment = comment
+
+			changes = append(changes, Change{
+				Index:     i,
+				CreatedBy: layer.History.CreatedBy,
+				Removed:   removed,
+			})
+		}
+
+		addenda = append(addenda, addendum)
+	}
+
+	if len(changes) == 0 {
+		return &misc.TempImage{Image: input}, nil, nil
+	}
+
+	if temp.Image, err = mutate.Append(result, addenda...); err != nil {
+		return fail(err)
+	}
+
+	return temp, changes, nil
+}
+
+func hasParentIn(name string, names map[string]struct{}) bool {
+	for dir := name; strings.Contains(dir, "/"); {
+		dir = dir[:strings.LastIndex(dir, "/")]
+		if _, ok := names[dir]; ok {
+			return true
+		}
+	}
+
+	return false
+}
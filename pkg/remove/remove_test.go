@@ -0,0 +1,134 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package remove_test
+
+import (
+	"archive/tar"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+
+	"github.com/homeport/forklift/internal/imagetest"
+	"github.com/homeport/forklift/pkg/remove"
+)
+
+var _ = Describe("Remove", func() {
+	var layers = [][]string{
+		{"etc/passwd", "root/.ssh/id_rsa"},
+		{"app/main", "app/config.pem"},
+		{"app/other"},
+	}
+
+	It("should append a whiteout layer for matching paths", func() {
+		input := imagetest.Image(layers...)
+
+		result, removed, err := remove.Whiteout(input, []string{"root/.ssh", "**/*.pem"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(removed).To(Equal([]string{"app/config.pem", "root/.ssh"}))
+
+		resultLayers, err := result.Layers()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resultLayers).To(HaveLen(4))
+		Expect(imagetest.Files(resultLayers[3])).To(ConsistOf("app/.wh.config.pem", "root/.wh..ssh"))
+	})
+
+	It("should rewrite the affected layers when purging", func() {
+		input := imagetest.Image(layers...)
+
+		result, changes, err := remove.Purge(input, []string{"root/.ssh", "**/*.pem"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changes).To(HaveLen(2))
+		Expect(changes[0].Index).To(Equal(0))
+		Expect(changes[0].Removed).To(Equal([]string{"root/.ssh/id_rsa"}))
+		Expect(changes[1].Index).To(Equal(1))
+
+		inputLayers, err := input.Layers()
+		Expect(err).ToNot(HaveOccurred())
+
+		resultLayers, err := result.Layers()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resultLayers).To(HaveLen(3))
+		Expect(imagetest.Files(resultLayers[0])).To(Equal([]string{"etc/passwd"}))
+		Expect(imagetest.Files(resultLayers[1])).To(Equal([]string{"app/main"}))
+
+		// unaffected layers are kept as-is
+		Expect(resultLayers[2].Digest()).To(Equal(imagetest.Must(inputLayers[2].Digest())))
+
+		configFile, err := result.ConfigFile()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(configFile.History[0].Comment).To(Equal("forklift removed 1 paths"))
+	})
+
+	It("should remove hardlinks to purged entries", func() {
+		input, err := mutate.Append(empty.Image, mutate.Addendum{
+			Layer: imagetest.LayerOf(
+				&tar.Header{Typeflag: tar.TypeReg, Name: "app/config.pem", Mode: 0644},
+				&tar.Header{Typeflag: tar.TypeLink, Name: "app/config", Linkname: "app/config.pem"},
+				&tar.Header{Typeflag: tar.TypeReg, Name: "app/main", Mode: 0755},
+			),
+			History: v1.History{CreatedBy: "layer a"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		result, changes, err := remove.Purge(input, []string{"**/*.pem"})
+		Expect(err).ToNot(HaveOccurred())
+		defer func() { _ = result.Close() }()
+
+		Expect(changes).To(HaveLen(1))
+		Expect(changes[0].Removed).To(Equal([]string{"app/config.pem", "app/config"}))
+
+		resultLayers, err := result.Layers()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(imagetest.Files(resultLayers[0])).To(Equal([]string{"app/main"}))
+	})
+
+	It("should create temporary files in the given directory", func() {
+		var tmp = GinkgoT().TempDir()
+
+		result, _, err := remove.Purge(imagetest.Image(layers...), []string{"**/*.pem"}, remove.WithTempDir(tmp))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(os.ReadDir(tmp)).To(HaveLen(1))
+		Expect(result.Close()).To(Succeed())
+		Expect(os.ReadDir(tmp)).To(BeEmpty())
+	})
+
+	It("should remove temporary files when the result is closed", func() {
+		var tmp = GinkgoT().TempDir()
+		GinkgoT().Setenv("TMPDIR", tmp)
+
+		whiteout, _, err := remove.Whiteout(imagetest.Image(layers...), []string{"**/*.pem"})
+		Expect(err).ToNot(HaveOccurred())
+
+		purged, _, err := remove.Purge(imagetest.Image(layers...), []string{"**/*.pem"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(os.ReadDir(tmp)).ToNot(BeEmpty())
+		Expect(whiteout.Close()).To(Succeed())
+		Expect(purged.Close()).To(Succeed())
+		Expect(os.ReadDir(tmp)).To(BeEmpty())
+	})
+})
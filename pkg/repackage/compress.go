@@ -0,0 +1,156 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package repackage
+
+import (
+	"fmt"
+
+	"github.com/homeport/forklift/pkg/misc"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// Recompress re-encodes all layers of the image using gzip with the
+// compression level (see WithCompressionLevel), non-distributable layers are
+// kept as-is
+func Recompress(image v1.Image, opts ...Option) (v1.Image, error) {
+	var o = newOptions(opts)
+
+	manifest, err := image.Manifest()
+	if err != nil {
+		return nil, err
+	}
+
+	configFile, err := image.ConfigFile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image config file: %w", err)
+	}
+
+	var layerMediaType = types.DockerLayer
+	if manifest.MediaType == types.OCIManifestSchema1 {
+		layerMediaType = types.OCILayer
+	}
+
+	addenda, err := misc.Addenda(image)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range addenda {
+		if addenda[i].Layer == nil {
+			continue
+		}
+
+		mediaType, err := addenda[i].Layer.MediaType()
+		if err != nil {
+			return nil, err
+		}
+
+		if !mediaType.IsDistributable() {
+			continue
+		}
+
+		addenda[i].Layer, err = tarball.LayerFromOpener(addenda[i].Layer.Uncompressed,
+			tarball.WithCompressionLevel(o.compressionLevel),
+			tarball.WithMediaType(layerMediaType),
+		)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := misc.EmptyImage(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	result = mutate.MediaType(result, manifest.MediaType)
+	result = mutate.ConfigMediaType(result, manifest.Config.MediaType)
+
+	result, err = mutate.Append(result, addenda...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(manifest.Annotations) > 0 {
+		result = mutate.Annotations(result, manifest.Annotations).(v1.Image)
+	}
+
+	return result, nil
+}
+
+// RecompressIndex recompresses all images of the index (see Recompress)
+func RecompressIndex(index v1.ImageIndex, opts ...Option) (v1.ImageIndex, error) {
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	var adds = make([]mutate.IndexAddendum, 0, len(manifest.Manifests))
+	for _, desc := range manifest.Manifests {
+		var add = mutate.IndexAddendum{
+			Descriptor: v1.Descriptor{
+				Platform:    desc.Platform,
+				Annotations: desc.Annotations,
+				URLs:        desc.URLs,
+			},
+		}
+
+		switch {
+		case desc.MediaType.IsIndex():
+			child, err := index.ImageIndex(desc.Digest)
+			if err != nil {
+				return nil, err
+			}
+
+			if add.Add, err = RecompressIndex(child, opts...); err != nil {
+				return nil, err
+			}
+
+		case desc.MediaType.IsImage():
+			image, err := index.Image(desc.Digest)
+			if err != nil {
+				return nil, err
+			}
+
+			if add.Add, err = Recompress(image, opts...); err != nil {
+				return nil, fmt.Errorf("failed to recompress image %s: %w", desc.Digest, err)
+			}
+
+		default:
+			return nil, fmt.Errorf("unsupported media type %s of index entry %s", desc.MediaType, desc.Digest)
+		}
+
+		adds = append(adds, add)
+	}
+
+	var result = mutate.AppendManifests(mutate.IndexMediaType(empty.Index, manifest.MediaType), adds...)
+	if len(manifest.Annotations) > 0 {
+		result = mutate.Annotations(result, manifest.Annotations).(v1.ImageIndex)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,85 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package repackage_test
+
+import (
+	"compress/gzip"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/homeport/forklift/pkg/repackage"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	randomimage "github.com/google/go-containerregistry/pkg/v1/random"
+
+	"github.com/homeport/forklift/internal/imagetest"
+)
+
+var _ = Describe("Recompress", func() {
+	It("should re-encode the layers without changing their content or the config", func() {
+		image := sampleImage("COPY base-layer /boot", "ENV FOO=BAR", "COPY run-0 /usr/local/bin")
+
+		result, err := repackage.Recompress(image)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(result.ConfigName()).To(Equal(imagetest.Must(image.ConfigName())))
+		Expect(imagetest.Must(result.Layers())).To(HaveLen(2))
+	})
+
+	It("should use the given compression level", func() {
+		image := sampleImage("COPY base-layer /boot")
+
+		fast, err := repackage.Recompress(image, repackage.WithCompressionLevel(gzip.BestSpeed))
+		Expect(err).ToNot(HaveOccurred())
+
+		stored, err := repackage.Recompress(image, repackage.WithCompressionLevel(gzip.NoCompression))
+		Expect(err).ToNot(HaveOccurred())
+
+		var size = func(image v1.Image) int64 {
+			return imagetest.Must(imagetest.Must(image.Layers())[0].Size())
+		}
+
+		Expect(size(stored)).To(BeNumerically(">", size(fast)))
+	})
+
+	It("should recompress all images of an index", func() {
+		index, err := randomimage.Index(256, 1, 2)
+		Expect(err).ToNot(HaveOccurred())
+
+		index = mutate.Annotations(index, map[string]string{"foo": "bar"}).(v1.ImageIndex)
+
+		result, err := repackage.RecompressIndex(index)
+		Expect(err).ToNot(HaveOccurred())
+
+		manifest, err := result.IndexManifest()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(manifest.Manifests).To(HaveLen(2))
+		Expect(manifest.Annotations).To(HaveKeyWithValue("foo", "bar"))
+
+		original, err := index.IndexManifest()
+		Expect(err).ToNot(HaveOccurred())
+		for i := range original.Manifests {
+			image := imagetest.Must(result.Image(manifest.Manifests[i].Digest))
+			Expect(image.ConfigName()).To(Equal(imagetest.Must(imagetest.Must(index.Image(original.Manifests[i].Digest)).ConfigName())))
+		}
+	})
+})
@@ -0,0 +1,245 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package repackage
+
+import (
+	archivetar "archive/tar"
+	"io"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/homeport/forklift/pkg/tar"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// Conflict describes a path that ends up with different content, because
+// the plan changes the order of the layers that provide it
+type Conflict struct {
+	Path string `json:"path"`
+
+	// Before is the original layer index that provides the path in the
+	// input image, After the one that provides it after applying the plan
+	Before int `json:"before"`
+	After  int `json:"after"`
+}
+
+// Conflicts compares which layer wins for each path before and after the
+// plan is applied. Whiteouts count for the path they remove and everything
+// below it, opaque whiteouts for everything below their directory, and a
+// file that replaces a directory (or the reverse) counts for the directory
+// content, too. Directories are only compared by their existence, as they
+// are commonly present in many layers. Paths that only disappear, because
+// the layer providing them is dropped, are an explicit change and are not
+// reported, but content of other layers that a dropped layer removed or
+// replaced is.
+func (p Plan) Conflicts() ([]Conflict, error) {
+	var all, kept []Action
+	var dropped = map[int]bool{}
+	for _, action := range p {
+		if action.Layer == nil {
+			continue
+		}
+
+		all = append(all, action)
+		if action.Intent == DROP {
+			dropped[action.OriginalIdx] = true
+			continue
+		}
+
+		kept = append(kept, action)
+	}
+
+	if len(moved(kept)) == 0 && len(dropped) == 0 {
+		return nil, nil
+	}
+
+	var original = slices.Clone(all)
+	slices.SortStableFunc(original, func(a, b Action) int { return a.OriginalIdx - b.OriginalIdx })
+
+	var changes = map[int][]change{}
+	for _, action := range all {
+		list, err := changesOf(action.Layer)
+		if err != nil {
+			return nil, err
+		}
+
+		changes[action.OriginalIdx] = list
+	}
+
+	var apply = func(actions []Action) fileSystem {
+		var fs = fileSystem{}
+		for _, action := range actions {
+			fs.apply(action.OriginalIdx, changes[action.OriginalIdx])
+		}
+
+		return fs
+	}
+
+	var before, after = apply(original), apply(kept)
+
+	var names = map[string]struct{}{}
+	for name := range before {
+		names[name] = struct{}{}
+	}
+
+	for name := range after {
+		names[name] = struct{}{}
+	}
+
+	var result []Conflict
+	for name := range names {
+		b, inBefore := before[name]
+		a, inAfter := after[name]
+		if inBefore && !inAfter && dropped[b.idx] {
+			continue
+		}
+
+		if !b.equal(a) {
+			result = append(result, Conflict{Path: name, Before: b.idx, After: a.idx})
+		}
+	}
+
+	slices.SortFunc(result, func(a, b Conflict) int { return strings.Compare(a.Path, b.Path) })
+	return result, nil
+}
+
+type changeKind int
+
+const (
+	addFile changeKind = iota
+	addDir
+	removePath
+	removeContent
+)
+
+// change is what a layer entry does to a path
+type change struct {
+	kind changeKind
+	name string
+}
+
+// node is the state of a path, and the layer that caused it
+type node struct {
+	idx     int
+	dir     bool
+	removed bool
+}
+
+// equal returns whether both nodes have the same visible result, where
+// directories only differ by their existence
+func (n node) equal(other node) bool {
+	switch {
+	case n.removed || other.removed:
+		return n.removed == other.removed
+
+	case n.dir || other.dir:
+		return n.dir == other.dir
+
+	default:
+		return n.idx == other.idx
+	}
+}
+
+// fileSystem maps the paths touched by any layer to their state
+type fileSystem map[string]node
+
+// apply applies the changes of one layer, whiteouts only affect the lower
+// layers, so they are applied first
+func (fs fileSystem) apply(idx int, changes []change) {
+	for _, c := range changes {
+		switch c.kind {
+		case removePath:
+			fs.removeBelow(idx, c.name)
+			fs[c.name] = node{idx: idx, removed: true}
+
+		case removeContent:
+			fs.removeBelow(idx, c.name)
+		}
+	}
+
+	for _, c := range changes {
+		switch c.kind {
+		case addFile:
+			fs.addParents(idx, c.name)
+			if existing := fs[c.name]; existing.dir && !existing.removed {
+				fs.removeBelow(idx, c.name)
+			}
+
+			fs[c.name] = node{idx: idx}
+
+		case addDir:
+			fs.addParents(idx, c.name)
+			if existing, ok := fs[c.name]; !ok || existing.removed || !existing.dir {
+				fs[c.name] = node{idx: idx, dir: true}
+			}
+		}
+	}
+}
+
+// removeBelow marks all existing paths below the directory as removed
+func (fs fileSystem) removeBelow(idx int, dir string) {
+	for name, existing := range fs {
+		if strings.HasPrefix(name, dir+"/") && !existing.removed {
+			fs[name] = node{idx: idx, removed: true}
+		}
+	}
+}
+
+// addParents makes sure that all parents of the path are directories
+func (fs fileSystem) addParents(idx int, name string) {
+	for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+		if existing, ok := fs[dir]; ok && existing.dir && !existing.removed {
+			return
+		}
+
+		fs[dir] = node{idx: idx, dir: true}
+	}
+}
+
+// changesOf returns the changes of the entries of a layer by normalized path
+func changesOf(layer v1.Layer) ([]change, error) {
+	var result []change
+	err := tar.Walk(layer, func(header *archivetar.Header, _ io.Reader) error {
+		var name = tar.Normalize(header.Name)
+		var dir, base = path.Split(name)
+		switch {
+		case name == "":
+
+		case base == tar.WhiteoutOpaque:
+			result = append(result, change{kind: removeContent, name: strings.TrimSuffix(dir, "/")})
+
+		case strings.HasPrefix(base, tar.WhiteoutPrefix):
+			result = append(result, change{kind: removePath, name: dir + strings.TrimPrefix(base, tar.WhiteoutPrefix)})
+
+		case header.Typeflag == archivetar.TypeDir:
+			result = append(result, change{kind: addDir, name: name})
+
+		default:
+			result = append(result, change{kind: addFile, name: name})
+		}
+
+		return nil
+	})
+
+	return result, err
+}
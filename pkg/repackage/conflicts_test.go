@@ -0,0 +1,143 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package repackage_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/homeport/forklift/pkg/misc"
+	"github.com/homeport/forklift/pkg/repackage"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+
+	"github.com/homeport/forklift/internal/imagetest"
+)
+
+var _ = Describe("Conflicts", func() {
+	var layers []misc.Layer
+
+	BeforeEach(func() {
+		image, err := mutate.Append(empty.Image,
+			mutate.Addendum{Layer: imagetest.Layer("etc/", "etc/config", "usr/", "usr/bin/tool"), History: v1.History{CreatedBy: "COPY base /"}},
+			mutate.Addendum{Layer: imagetest.Layer("etc/", "etc/config"), History: v1.History{CreatedBy: "COPY config /etc"}},
+			mutate.Addendum{Layer: imagetest.Layer("usr/", "usr/lib/lib.so"), History: v1.History{CreatedBy: "COPY lib /usr/lib"}},
+			mutate.Addendum{Layer: imagetest.Layer("usr/", "usr/lib/.wh.lib.so"), History: v1.History{CreatedBy: "RUN rm /usr/lib/lib.so"}},
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		layers, err = misc.Layers(image)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	var conflicts = func(text string) []repackage.Conflict {
+		GinkgoHelper()
+
+		plan, err := repackage.ParsePlan(text, layers)
+		Expect(err).ToNot(HaveOccurred())
+
+		result, err := plan.Conflicts()
+		Expect(err).ToNot(HaveOccurred())
+
+		return result
+	}
+
+	It("should not report anything for plans that keep the order", func() {
+		Expect(conflicts("pick 0\npick 1\nfixup 2\npick 3\n")).To(BeEmpty())
+	})
+
+	It("should not report re-ordered layers that touch different paths", func() {
+		Expect(conflicts("pick 0\npick 2\npick 1\npick 3\n")).To(BeEmpty())
+	})
+
+	It("should report paths where a different layer wins", func() {
+		Expect(conflicts("pick 1\npick 0\npick 2\npick 3\n")).To(Equal([]repackage.Conflict{
+			{Path: "etc/config", Before: 1, After: 0},
+		}))
+	})
+
+	It("should consider whiteouts as changes of the path", func() {
+		Expect(conflicts("pick 0\npick 1\npick 3\npick 2\n")).To(Equal([]repackage.Conflict{
+			{Path: "usr/lib/lib.so", Before: 3, After: 2},
+		}))
+	})
+
+	It("should ignore paths that disappear with dropped layers", func() {
+		Expect(conflicts("pick 0\npick 1\ndrop 2\npick 3\n")).To(BeEmpty())
+	})
+
+	It("should report content that a dropped layer replaced", func() {
+		Expect(conflicts("pick 0\npick 2\ndrop 1\npick 3\n")).To(Equal([]repackage.Conflict{
+			{Path: "etc/config", Before: 1, After: 0},
+		}))
+	})
+
+	It("should treat layers left out of the plan as dropped", func() {
+		Expect(conflicts("pick 0\npick 1\npick 2\n")).To(Equal([]repackage.Conflict{
+			{Path: "usr/lib/lib.so", Before: 3, After: 2},
+		}))
+	})
+
+	Context("directories", func() {
+		var conflictsOf = func(text string, addenda ...mutate.Addendum) []repackage.Conflict {
+			GinkgoHelper()
+
+			image, err := mutate.Append(empty.Image, addenda...)
+			Expect(err).ToNot(HaveOccurred())
+
+			layers, err = misc.Layers(image)
+			Expect(err).ToNot(HaveOccurred())
+
+			return conflicts(text)
+		}
+
+		It("should consider a directory whiteout as a change of the files below it", func() {
+			Expect(conflictsOf("pick 1\npick 0\n",
+				mutate.Addendum{Layer: imagetest.Layer("opt/data/file"), History: v1.History{CreatedBy: "COPY data /opt/data"}},
+				mutate.Addendum{Layer: imagetest.Layer("opt/.wh.data"), History: v1.History{CreatedBy: "RUN rm -rf /opt/data"}},
+			)).To(Equal([]repackage.Conflict{
+				{Path: "opt/data", Before: 1, After: 0},
+				{Path: "opt/data/file", Before: 1, After: 0},
+			}))
+		})
+
+		It("should consider an opaque whiteout as a change of the files below the directory", func() {
+			Expect(conflictsOf("pick 1\npick 0\n",
+				mutate.Addendum{Layer: imagetest.Layer("var/cache/old"), History: v1.History{CreatedBy: "COPY cache /var/cache"}},
+				mutate.Addendum{Layer: imagetest.Layer("var/cache/", "var/cache/.wh..wh..opq", "var/cache/new"), History: v1.History{CreatedBy: "COPY fresh /var/cache"}},
+			)).To(Equal([]repackage.Conflict{
+				{Path: "var/cache/old", Before: 1, After: 0},
+			}))
+		})
+
+		It("should report a file that replaces a directory", func() {
+			Expect(conflictsOf("pick 1\npick 0\n",
+				mutate.Addendum{Layer: imagetest.Layer("etc/app"), History: v1.History{CreatedBy: "COPY app /etc/app"}},
+				mutate.Addendum{Layer: imagetest.Layer("etc/.wh.app", "etc/app/", "etc/app/conf"), History: v1.History{CreatedBy: "COPY conf /etc/app/"}},
+			)).To(Equal([]repackage.Conflict{
+				{Path: "etc/app", Before: 1, After: 0},
+				{Path: "etc/app/conf", Before: 1, After: 0},
+			}))
+		})
+	})
+})
@@ -0,0 +1,140 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package repackage
+
+// PreviewLayer describes one entry of the image that would be created by
+// running a plan
+type PreviewLayer struct {
+	// Sources lists the original layer indexes that feed into this layer
+	Sources []int `json:"sources"`
+
+	// EmptyLayer indicates a history entry without a layer blob
+	EmptyLayer bool `json:"emptyLayer"`
+
+	// Locked indicates a base image layer that is kept unchanged
+	Locked bool `json:"locked"`
+
+	// CreatedBy is the (combined) created by string of the history entry
+	CreatedBy string `json:"createdBy"`
+
+	// EstimatedSize is the compressed size of the layer, which is exact for
+	// picked layers and the sum of the input sizes for combined layers
+	EstimatedSize int64 `json:"estimatedSize"`
+}
+
+// Preview is the result of a dry-run of a plan
+type Preview struct {
+	Layers []PreviewLayer
+
+	// Moved lists the actions whose history entries change their position
+	// relative to the other entries
+	Moved []Action
+
+	// Dropped lists the actions that are removed from the image
+	Dropped []Action
+}
+
+// DryRun validates the plan and previews the resulting layer structure
+// without extracting or writing any layer data
+func DryRun(plan Plan) (*Preview, error) {
+	stages, err := plan.stages()
+	if err != nil {
+		return nil, err
+	}
+
+	var preview Preview
+	for _, stage := range stages {
+		var layer = PreviewLayer{
+			EmptyLayer: len(stage.layers()) == 0,
+			Locked:     stage[0].Locked,
+			CreatedBy:  stage.createdBy(),
+		}
+
+		for _, action := range stage {
+			layer.Sources = append(layer.Sources, action.OriginalIdx)
+
+			if action.Layer != nil {
+				size, err := action.Layer.Size()
+				if err != nil {
+					return nil, err
+				}
+
+				layer.EstimatedSize += size
+			}
+		}
+
+		preview.Layers = append(preview.Layers, layer)
+	}
+
+	var kept Plan
+	for _, action := range plan {
+		if action.Intent == DROP {
+			preview.Dropped = append(preview.Dropped, action)
+			continue
+		}
+
+		kept = append(kept, action)
+	}
+
+	preview.Moved = moved(kept)
+
+	return &preview, nil
+}
+
+// moved returns the actions that are not part of the longest sequence of
+// actions which kept their original relative order
+func moved(plan Plan) []Action {
+	if len(plan) == 0 {
+		return nil
+	}
+
+	// length of the longest increasing sequence ending at i and its predecessor
+	var length = make([]int, len(plan))
+	var prev = make([]int, len(plan))
+
+	var end int
+	for i := range plan {
+		length[i], prev[i] = 1, -1
+		for j := 0; j < i; j++ {
+			if plan[j].OriginalIdx < plan[i].OriginalIdx && length[j]+1 > length[i] {
+				length[i], prev[i] = length[j]+1, j
+			}
+		}
+
+		if length[i] > length[end] {
+			end = i
+		}
+	}
+
+	var inOrder = make([]bool, len(plan))
+	for i := end; i >= 0; i = prev[i] {
+		inOrder[i] = true
+	}
+
+	var result []Action
+	for i := range plan {
+		if !inOrder[i] {
+			result = append(result, plan[i])
+		}
+	}
+
+	return result
+}
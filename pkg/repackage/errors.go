@@ -20,8 +20,83 @@
 
 package repackage
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
 
 // TODO Check wording
 
-var errFixupOnEmptyLayer = errors.New("cannot use fixup for an empty layer")
+var (
+	// ErrEmptyPlan is the reason for a plan without any actions
+	ErrEmptyPlan = errors.New("plan does not contain any actions")
+
+	// ErrFixupWithoutPick is the reason for a fixup at the start of a plan
+	ErrFixupWithoutPick = errors.New("cannot use fixup without a preceding pick")
+
+	// ErrFixupIntoEmptyLayer is the reason for a fixup of a layer into an
+	// empty layer (i.e. ENV), which cannot hold files
+	ErrFixupIntoEmptyLayer = errors.New("cannot use fixup to combine a layer into an empty layer")
+
+	// ErrLockedLayer is the reason for an action that alters a layer of the
+	// base image
+	ErrLockedLayer = errors.New("layer belongs to the base image and must be picked unchanged at its original position")
+)
+
+// PlanError describes why a plan or one of its actions is invalid
+type PlanError struct {
+	// Line of the plan text, zero if unknown
+	Line int
+
+	// Action the error refers to, nil if it is not about a single action
+	Action *Action
+
+	Reason error
+}
+
+func (e *PlanError) Error() string {
+	var parts []string
+	if e.Line > 0 {
+		parts = append(parts, fmt.Sprintf("line %d", e.Line))
+	}
+
+	if e.Action != nil {
+		parts = append(parts, fmt.Sprintf("%s %d", e.Action.Intent, e.Action.OriginalIdx))
+	}
+
+	return strings.Join(append(parts, e.Reason.Error()), ": ")
+}
+
+func (e *PlanError) Unwrap() error { return e.Reason }
+
+// LayerError describes a failed operation on a layer of the input image
+type LayerError struct {
+	// Index of the layer in the input image (see misc.Layers)
+	Index int
+
+	// Digest of the layer, if it is known
+	Digest v1.Hash
+
+	// Op is the failed operation, i.e. extract or compress
+	Op string
+
+	Err error
+}
+
+func (e *LayerError) Error() string {
+	return fmt.Sprintf("failed to %s layer %d: %v", e.Op, e.Index, e.Err)
+}
+
+func (e *LayerError) Unwrap() error { return e.Err }
+
+func layerError(op string, action Action, err error) error {
+	var result = &LayerError{Index: action.OriginalIdx, Op: op, Err: err}
+	if action.Layer != nil {
+		result.Digest, _ = action.Layer.Digest()
+	}
+
+	return result
+}
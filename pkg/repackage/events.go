@@ -0,0 +1,90 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package repackage
+
+import v1 "github.com/google/go-containerregistry/pkg/v1"
+
+// Event is passed to the observer while an image is repackaged, it is one
+// of StageStarted, LayerExtracted, LayerCompressed, AddendumAppended, or
+// DigestComputed
+type Event interface {
+	event()
+}
+
+// Observer is called synchronously for each event, it should return quickly
+type Observer func(Event)
+
+// WithObserver calls the observer for each event while repackaging
+func WithObserver(observer Observer) Option {
+	return func(o *options) { o.observer = observer }
+}
+
+// StageStarted is sent before a stage of the plan is processed
+type StageStarted struct {
+	// Stage is the index of the stage, Stages the total number of stages
+	Stage  int
+	Stages int
+
+	// Sources lists the original layer indexes that feed into the stage
+	Sources []int
+}
+
+// LayerExtracted is sent after a layer was extracted for combining it
+type LayerExtracted struct {
+	Stage int
+
+	// Source is the original layer index
+	Source int
+
+	// Bytes is the number of compressed bytes that were read
+	Bytes int64
+}
+
+// LayerCompressed is sent after a combined layer was compressed
+type LayerCompressed struct {
+	Stage  int
+	Digest v1.Hash
+	Size   int64
+}
+
+// AddendumAppended is sent after the result of a stage was appended
+type AddendumAppended struct {
+	Stage      int
+	EmptyLayer bool
+	CreatedBy  string
+}
+
+// DigestComputed is sent once the digest of the resulting image is known
+type DigestComputed struct {
+	Digest v1.Hash
+}
+
+func (StageStarted) event()     {}
+func (LayerExtracted) event()   {}
+func (LayerCompressed) event()  {}
+func (AddendumAppended) event() {}
+func (DigestComputed) event()   {}
+
+func (o options) notify(event Event) {
+	if o.observer != nil {
+		o.observer(event)
+	}
+}
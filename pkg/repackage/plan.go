@@ -0,0 +1,284 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package repackage
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/homeport/forklift/pkg/misc"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+const planHelp = `
+# Commands:
+# pick  <layer> = use layer
+# fixup <layer> = combine layer with the previous layer
+# drop  <layer> = remove layer (same as removing the line)
+#
+# Empty layers (i.e. ENV, LABEL, CMD) only carry history, they can be
+# re-ordered and combined with each other or folded into a previous layer.
+# Lines can be re-ordered, they are executed from top to bottom.
+# Locked layers belong to the base image and must stay unchanged.
+`
+
+// NewPlan creates a plan that picks all given layers in their original
+// order, which would result in an unchanged image
+func NewPlan(layers []misc.Layer) Plan {
+	var plan = make(Plan, 0, len(layers))
+	for i := range layers {
+		plan = append(plan, Action{
+			Line:        i + 1,
+			OriginalIdx: i,
+			Intent:      PICK,
+			Layer:       layers[i].Layer,
+			History:     layers[i].History,
+			Locked:      layers[i].Base,
+		})
+	}
+
+	return plan
+}
+
+// MarshalText renders the plan in its textual form, one action per line
+// followed by a short help section, which can be read using ParsePlan
+func (p Plan) MarshalText() ([]byte, error) {
+	text, err := p.text()
+	if err != nil {
+		return nil, err
+	}
+
+	return append(text, planHelp...), nil
+}
+
+// text renders the plan actions, one action per line
+func (p Plan) text() ([]byte, error) {
+	var buf bytes.Buffer
+	for _, action := range p {
+		var desc string
+		if action.Layer != nil {
+			diffID, err := action.Layer.DiffID()
+			if err != nil {
+				return nil, err
+			}
+
+			desc = diffID.String()
+		}
+
+		if desc == "" && action.History != nil && action.History.EmptyLayer {
+			desc = "(empty layer)"
+			if action.History.CreatedBy != "" {
+				desc = fmt.Sprintf("(empty layer) %s", action.History.CreatedBy)
+			}
+		}
+
+		if action.Locked {
+			desc += " (locked)"
+		}
+
+		fmt.Fprintf(&buf, "%-6s %3d %s\n", action.Intent, action.OriginalIdx, desc)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ParsePlan reads the textual form of a plan, where each line references
+// one of the given layers by its index. Empty lines and lines starting
+// with a hash are ignored. All base image layers have to be referenced,
+// other layers that are not referenced are dropped, for which drop actions
+// without line number are added to the end of the plan.
+func ParsePlan(text string, layers []misc.Layer) (Plan, error) {
+	var plan Plan
+	var referenced = make([]bool, len(layers))
+	var scanner = bufio.NewScanner(strings.NewReader(text))
+	for line := 1; scanner.Scan(); line++ {
+		var entry = strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+
+		parts := strings.Fields(entry)
+		if len(parts) < 2 {
+			return nil, &PlanError{Line: line, Reason: errors.New("plan entry doesn't match the expected format of <intention> <layer>")}
+		}
+
+		intent := Intention(parts[0])
+		if !intent.valid() {
+			return nil, &PlanError{Line: line, Reason: fmt.Errorf("unknown intention %q", parts[0])}
+		}
+
+		idx, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, &PlanError{Line: line, Reason: fmt.Errorf("invalid layer index %q: %w", parts[1], err)}
+		}
+
+		if idx < 0 || idx >= len(layers) {
+			return nil, &PlanError{Line: line, Reason: fmt.Errorf("layer index %d is out of range, image has %d layers", idx, len(layers))}
+		}
+
+		referenced[idx] = true
+		plan = append(plan, Action{
+			Line:        line,
+			OriginalIdx: idx,
+			Intent:      intent,
+			Layer:       layers[idx].Layer,
+			History:     layers[idx].History,
+			Locked:      layers[idx].Base,
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range layers {
+		switch {
+		case referenced[i]:
+			continue
+
+		case layers[i].Base:
+			return nil, &PlanError{Reason: fmt.Errorf("layer %d belongs to the base image and cannot be removed", i)}
+		}
+
+		plan = append(plan, Action{
+			OriginalIdx: i,
+			Intent:      DROP,
+			Layer:       layers[i].Layer,
+			History:     layers[i].History,
+		})
+	}
+
+	return plan, nil
+}
+
+// Validate checks whether the plan can be executed
+func (p Plan) Validate() error {
+	_, err := p.stages()
+	return err
+}
+
+// stage is a group of actions that end up as one entry in the resulting
+// image, which is a pick followed by any number of fixups
+type stage []Action
+
+// stages groups the plan actions into stages and validates them on the way
+func (p Plan) stages() ([]stage, error) {
+	if len(p) == 0 {
+		return nil, &PlanError{Reason: ErrEmptyPlan}
+	}
+
+	// position of the action among the kept actions, which is where locked
+	// layers have to stay
+	var position int
+
+	var result []stage
+	for _, action := range p {
+		if action.Locked && (action.Intent != PICK || action.OriginalIdx != position) {
+			return nil, &PlanError{Line: action.Line, Action: &action, Reason: ErrLockedLayer}
+		}
+
+		if action.Intent != DROP {
+			position++
+		}
+
+		switch action.Intent {
+		case PICK:
+			result = append(result, stage{action})
+
+		case DROP:
+			continue
+
+		case FIXUP:
+			if len(result) == 0 {
+				return nil, &PlanError{Line: action.Line, Action: &action, Reason: ErrFixupWithoutPick}
+			}
+
+			var last = &result[len(result)-1]
+			if action.Layer != nil && (*last)[0].Layer == nil {
+				return nil, &PlanError{Line: action.Line, Action: &action, Reason: ErrFixupIntoEmptyLayer}
+			}
+
+			if (*last)[0].Locked {
+				return nil, &PlanError{Line: action.Line, Action: &action, Reason: fmt.Errorf("cannot combine with layer %d: %w", (*last)[0].OriginalIdx, ErrLockedLayer)}
+			}
+
+			*last = append(*last, action)
+
+		default:
+			return nil, &PlanError{Line: action.Line, Action: &action, Reason: fmt.Errorf("unknown intention %q", action.Intent)}
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, &PlanError{Reason: ErrEmptyPlan}
+	}
+
+	return result, nil
+}
+
+// sources returns the original layer indexes of all actions
+func (s stage) sources() []int {
+	var sources = make([]int, len(s))
+	for i, action := range s {
+		sources[i] = action.OriginalIdx
+	}
+
+	return sources
+}
+
+// layers returns the layers of all actions, skipping empty layers
+func (s stage) layers() []v1.Layer {
+	var layers []v1.Layer
+	for _, action := range s {
+		if action.Layer != nil {
+			layers = append(layers, action.Layer)
+		}
+	}
+
+	return layers
+}
+
+// createdBy returns the combined created by strings of all actions
+func (s stage) createdBy() string {
+	var createdBy []string
+	for _, action := range s {
+		if action.History != nil {
+			createdBy = append(createdBy, action.History.CreatedBy)
+		}
+	}
+
+	return strings.Join(createdBy, ", ")
+}
+
+func (i Intention) valid() bool {
+	switch i {
+	case PICK, FIXUP, DROP:
+		return true
+
+	default:
+		return false
+	}
+}
@@ -0,0 +1,464 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package repackage_test
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/homeport/forklift/pkg/misc"
+	"github.com/homeport/forklift/pkg/repackage"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+
+	"github.com/homeport/forklift/internal/imagetest"
+)
+
+var _ = Describe("Plan", func() {
+	var layers []misc.Layer
+
+	BeforeEach(func() {
+		var err error
+		layers, err = misc.Layers(sampleImage(
+			"COPY base-layer /boot",
+			"COPY update /etc",
+			"COPY run-0 /usr/local/bin",
+			"ENV FOO=BAR",
+			"COPY run-1 /opt/tool",
+		))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	Context("text representation", func() {
+		It("should parse its own textual form", func() {
+			var plan = repackage.NewPlan(layers)
+
+			text, err := plan.MarshalText()
+			Expect(err).ToNot(HaveOccurred())
+
+			parsed, err := repackage.ParsePlan(string(text), layers)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(parsed).To(Equal(plan))
+		})
+
+		It("should fail on layer indexes that are out of range", func() {
+			_, err := repackage.ParsePlan("pick 5\n", layers)
+			Expect(err).To(MatchError(ContainSubstring("line 1: layer index 5 is out of range")))
+		})
+
+		It("should fail on unknown intentions", func() {
+			_, err := repackage.ParsePlan("pick 0\nsquash 1\n", layers)
+			Expect(err).To(MatchError(ContainSubstring(`line 2: unknown intention "squash"`)))
+		})
+	})
+
+	Context("dry-run", func() {
+		It("should preview the resulting layers without touching layer data", func() {
+			plan, err := repackage.ParsePlan("pick 0\npick 1\nfixup 2\npick 4\npick 3\n", layers)
+			Expect(err).ToNot(HaveOccurred())
+
+			preview, err := repackage.DryRun(plan)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(preview.Layers).To(HaveLen(4))
+
+			Expect(preview.Layers[1].Sources).To(Equal([]int{1, 2}))
+			Expect(preview.Layers[1].CreatedBy).To(Equal("COPY update /etc, COPY run-0 /usr/local/bin"))
+
+			size1, err := layers[1].Size()
+			Expect(err).ToNot(HaveOccurred())
+			size2, err := layers[2].Size()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(preview.Layers[1].EstimatedSize).To(Equal(size1 + size2))
+
+			Expect(preview.Layers[3].EmptyLayer).To(BeTrue())
+
+			Expect(preview.Moved).To(HaveLen(1))
+			Expect(preview.Moved[0].OriginalIdx).To(Equal(3))
+		})
+
+		It("should reject a plan that starts with a fixup", func() {
+			plan, err := repackage.ParsePlan("fixup 0\npick 1\n", layers)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = repackage.DryRun(plan)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should reject a fixup of a layer into an empty layer", func() {
+			plan, err := repackage.ParsePlan("pick 3\nfixup 2\n", layers)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(plan.Validate()).To(HaveOccurred())
+		})
+
+		It("should list dropped entries", func() {
+			plan, err := repackage.ParsePlan("pick 0\npick 1\npick 2\ndrop 3\npick 4\n", layers)
+			Expect(err).ToNot(HaveOccurred())
+
+			preview, err := repackage.DryRun(plan)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(preview.Layers).To(HaveLen(4))
+			Expect(preview.Moved).To(BeEmpty())
+			Expect(preview.Dropped).To(HaveLen(1))
+			Expect(preview.Dropped[0].OriginalIdx).To(Equal(3))
+		})
+
+		It("should list entries left out of the plan as dropped", func() {
+			plan, err := repackage.ParsePlan("pick 0\npick 1\npick 2\npick 4\n", layers)
+			Expect(err).ToNot(HaveOccurred())
+
+			preview, err := repackage.DryRun(plan)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(preview.Layers).To(HaveLen(4))
+			Expect(preview.Dropped).To(HaveLen(1))
+			Expect(preview.Dropped[0].OriginalIdx).To(Equal(3))
+		})
+
+		It("should report the plan line and action of invalid entries", func() {
+			plan, err := repackage.ParsePlan("# comment\n\npick 3\nfixup 2\n", layers)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = plan.Validate()
+			Expect(err).To(MatchError(repackage.ErrFixupIntoEmptyLayer))
+
+			var planErr *repackage.PlanError
+			Expect(errors.As(err, &planErr)).To(BeTrue())
+			Expect(planErr.Line).To(Equal(4))
+			Expect(planErr.Action.OriginalIdx).To(Equal(2))
+			Expect(err.Error()).To(Equal("line 4: fixup 2: " + repackage.ErrFixupIntoEmptyLayer.Error()))
+		})
+
+		It("should reject a plan that drops everything", func() {
+			plan, err := repackage.ParsePlan("drop 0\ndrop 1\n", layers)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(plan.Validate()).To(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("Empty layer history entries", func() {
+	var (
+		image  v1.Image
+		layers []misc.Layer
+	)
+
+	BeforeEach(func() {
+		image = sampleImage(
+			"COPY base-layer /boot",
+			"ENV FOO=BAR",
+			"COPY run-0 /usr/local/bin",
+			"ENV BAR=FOO",
+			"LABEL foo=bar",
+		)
+
+		var err error
+		layers, err = misc.Layers(image)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	var repackaged = func(text string) (*v1.ConfigFile, []v1.Layer) {
+		GinkgoHelper()
+
+		plan, err := repackage.ParsePlan(text, layers)
+		Expect(err).ToNot(HaveOccurred())
+
+		result, err := repackage.Image(context.Background(), image, plan)
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(result.Close)
+
+		configFile, err := result.ConfigFile()
+		Expect(err).ToNot(HaveOccurred())
+
+		resultLayers, err := result.Layers()
+		Expect(err).ToNot(HaveOccurred())
+
+		return configFile, resultLayers
+	}
+
+	It("should move empty layer entries without touching the layers", func() {
+		configFile, resultLayers := repackaged("pick 0\npick 2\npick 1\npick 3\npick 4\n")
+		Expect(resultLayers).To(HaveLen(2))
+		Expect(configFile.History).To(HaveLen(5))
+		Expect(configFile.History[2].CreatedBy).To(Equal("ENV FOO=BAR"))
+		Expect(configFile.History[2].EmptyLayer).To(BeTrue())
+
+		for i, layer := range resultLayers {
+			expected, err := layers[[]int{0, 2}[i]].DiffID()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(layer.DiffID()).To(Equal(expected))
+		}
+	})
+
+	It("should combine empty layer entries into one entry", func() {
+		configFile, resultLayers := repackaged("pick 0\npick 2\npick 1\nfixup 3\nfixup 4\n")
+		Expect(resultLayers).To(HaveLen(2))
+		Expect(configFile.History).To(HaveLen(3))
+		Expect(configFile.History[2].EmptyLayer).To(BeTrue())
+		Expect(configFile.History[2].CreatedBy).To(Equal("ENV FOO=BAR, ENV BAR=FOO, LABEL foo=bar"))
+	})
+
+	It("should fold empty layer entries into a layer without changing it", func() {
+		configFile, resultLayers := repackaged("pick 0\npick 2\nfixup 1\n")
+		Expect(resultLayers).To(HaveLen(2))
+		Expect(configFile.History).To(HaveLen(2))
+		Expect(configFile.History[1].EmptyLayer).To(BeFalse())
+		Expect(configFile.History[1].CreatedBy).To(Equal("COPY run-0 /usr/local/bin, ENV FOO=BAR"))
+
+		expected, err := layers[2].DiffID()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resultLayers[1].DiffID()).To(Equal(expected))
+	})
+
+	It("should drop empty layer entries", func() {
+		configFile, resultLayers := repackaged("pick 0\ndrop 1\npick 2\ndrop 3\npick 4\n")
+		Expect(resultLayers).To(HaveLen(2))
+		Expect(configFile.History).To(HaveLen(3))
+		Expect(configFile.History[2].CreatedBy).To(Equal("LABEL foo=bar"))
+	})
+})
+
+var _ = Describe("Base image layers", func() {
+	var (
+		image  v1.Image
+		layers []misc.Layer
+	)
+
+	BeforeEach(func() {
+		base := sampleImage(
+			"COPY base-layer /boot",
+			"ENV PATH=/bin",
+		)
+
+		var err error
+		image, err = mutate.Append(base,
+			mutate.Addendum{Layer: randomLayer(), History: v1.History{CreatedBy: "COPY update /etc"}},
+			mutate.Addendum{Layer: randomLayer(), History: v1.History{CreatedBy: "COPY run-0 /usr/local/bin"}},
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		boundary, err := misc.BaseBoundary(image, base)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(boundary).To(Equal(2))
+
+		layers, err = misc.Layers(image)
+		Expect(err).ToNot(HaveOccurred())
+		for i := 0; i < boundary; i++ {
+			layers[i].Base = true
+		}
+	})
+
+	It("should not match an unrelated base image", func() {
+		_, err := misc.BaseBoundary(image, sampleImage("COPY other /"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should mark base image layers as locked", func() {
+		var plan = repackage.NewPlan(layers)
+		Expect(plan[0].Locked).To(BeTrue())
+		Expect(plan[1].Locked).To(BeTrue())
+		Expect(plan[2].Locked).To(BeFalse())
+
+		text, err := plan.MarshalText()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(text)).To(ContainSubstring("(locked)"))
+	})
+
+	It("should reject plans that alter base image layers", func() {
+		for _, text := range []string{
+			"pick 0\nfixup 1\npick 2\npick 3\n",
+			"pick 0\npick 1\nfixup 2\npick 3\n",
+			"pick 1\npick 0\npick 2\npick 3\n",
+		} {
+			plan, err := repackage.ParsePlan(text, layers)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(plan.Validate()).To(HaveOccurred(), text)
+		}
+
+		_, err := repackage.ParsePlan("pick 1\npick 2\npick 3\n", layers)
+		Expect(err).To(MatchError(ContainSubstring("layer 0 belongs to the base image")))
+	})
+
+	It("should accept plans that only change layers on top of the base image", func() {
+		plan, err := repackage.ParsePlan("pick 0\npick 1\npick 2\nfixup 3\n", layers)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(plan.Validate()).To(Succeed())
+	})
+
+	It("should accept dropped layers in front of the base image layers", func() {
+		plan, err := repackage.ParsePlan("drop 2\npick 0\npick 1\npick 3\n", layers)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(plan.Validate()).To(Succeed())
+	})
+})
+
+var _ = Describe("Temporary files", func() {
+	var (
+		image  v1.Image
+		plan   repackage.Plan
+		tmpDir string
+	)
+
+	BeforeEach(func() {
+		image = sampleImage(
+			"COPY base-layer /boot",
+			"COPY update /etc",
+			"COPY run-0 /usr/local/bin",
+		)
+
+		layers, err := misc.Layers(image)
+		Expect(err).ToNot(HaveOccurred())
+
+		plan, err = repackage.ParsePlan("pick 0\npick 1\nfixup 2\n", layers)
+		Expect(err).ToNot(HaveOccurred())
+
+		tmpDir = GinkgoT().TempDir()
+	})
+
+	It("should keep temporary files until the result is closed", func() {
+		result, err := repackage.Image(context.Background(), image, plan, repackage.WithTempDir(tmpDir))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.ReadDir(tmpDir)).ToNot(BeEmpty())
+
+		_, err = result.Digest()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(result.Close()).To(Succeed())
+		Expect(os.ReadDir(tmpDir)).To(BeEmpty())
+	})
+
+	It("should report progress to the observer", func() {
+		var events []repackage.Event
+		result, err := repackage.Image(context.Background(), image, plan,
+			repackage.WithTempDir(tmpDir),
+			repackage.WithObserver(func(event repackage.Event) { events = append(events, event) }),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(result.Close)
+
+		Expect(events).To(HaveLen(8))
+		Expect(events[0]).To(Equal(repackage.StageStarted{Stage: 0, Stages: 2, Sources: []int{0}}))
+		Expect(events[1]).To(BeAssignableToTypeOf(repackage.AddendumAppended{}))
+		Expect(events[2]).To(Equal(repackage.StageStarted{Stage: 1, Stages: 2, Sources: []int{1, 2}}))
+		Expect(events[3]).To(BeAssignableToTypeOf(repackage.LayerExtracted{}))
+		Expect(events[3].(repackage.LayerExtracted).Bytes).To(BeNumerically(">", 0))
+		Expect(events[4]).To(BeAssignableToTypeOf(repackage.LayerExtracted{}))
+		Expect(events[5]).To(BeAssignableToTypeOf(repackage.LayerCompressed{}))
+		Expect(events[6]).To(BeAssignableToTypeOf(repackage.AddendumAppended{}))
+
+		layers, err := result.Layers()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(layers[1].Digest()).To(Equal(events[5].(repackage.LayerCompressed).Digest))
+
+		Expect(events[7]).To(BeAssignableToTypeOf(repackage.DigestComputed{}))
+		Expect(result.Digest()).To(Equal(events[7].(repackage.DigestComputed).Digest))
+	})
+
+	It("should stop and remove temporary files when the context is cancelled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := repackage.Image(ctx, image, plan, repackage.WithTempDir(tmpDir))
+		Expect(err).To(MatchError(context.Canceled))
+		Expect(os.ReadDir(tmpDir)).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Provenance", func() {
+	It("should record the source image, plan, and layer mapping", func() {
+		image := sampleImage(
+			"COPY base-layer /boot",
+			"ENV FOO=BAR",
+			"COPY update /etc",
+			"COPY run-0 /usr/local/bin",
+		)
+
+		layers, err := misc.Layers(image)
+		Expect(err).ToNot(HaveOccurred())
+
+		plan, err := repackage.ParsePlan("pick 0\npick 2\nfixup 3\npick 1\n", layers)
+		Expect(err).ToNot(HaveOccurred())
+
+		var source = imagetest.Must(image.Digest())
+		result, err := repackage.Image(context.Background(), image, plan, repackage.WithProvenance(), repackage.WithSourceDigest(source))
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(result.Close)
+
+		provenance, err := repackage.ReadProvenance(result)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(provenance).To(Equal(result.Provenance))
+
+		Expect(provenance.Source).To(Equal(&source))
+		Expect(provenance.SourceImageID).To(Equal(imagetest.Must(image.ConfigName())))
+		Expect(provenance.Plan).To(HavePrefix("pick     0 "))
+		Expect(provenance.Plan).ToNot(ContainSubstring("# Commands"))
+
+		resultLayers, err := result.Layers()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(provenance.Layers).To(Equal([]repackage.LayerProvenance{
+			{DiffID: imagetest.Must(resultLayers[0].DiffID()), Sources: []v1.Hash{imagetest.Must(layers[0].DiffID())}},
+			{DiffID: imagetest.Must(resultLayers[1].DiffID()), Sources: []v1.Hash{imagetest.Must(layers[2].DiffID()), imagetest.Must(layers[3].DiffID())}},
+		}))
+
+		manifest, err := result.Manifest()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(manifest.Annotations).To(HaveKeyWithValue(repackage.ProvenanceSourceKey, provenance.Source.String()))
+	})
+
+	It("should only record the image ID of a source without known manifest digest", func() {
+		image := sampleImage("COPY base-layer /boot")
+
+		layers, err := misc.Layers(image)
+		Expect(err).ToNot(HaveOccurred())
+
+		result, err := repackage.Image(context.Background(), image, repackage.NewPlan(layers), repackage.WithProvenance())
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(result.Close)
+
+		provenance, err := repackage.ReadProvenance(result)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(provenance.Source).To(BeNil())
+		Expect(provenance.SourceImageID).To(Equal(imagetest.Must(image.ConfigName())))
+
+		manifest, err := result.Manifest()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(manifest.Annotations).ToNot(HaveKey(repackage.ProvenanceSourceKey))
+	})
+
+	It("should not record anything unless requested", func() {
+		image := sampleImage("COPY base-layer /boot")
+
+		layers, err := misc.Layers(image)
+		Expect(err).ToNot(HaveOccurred())
+
+		result, err := repackage.Image(context.Background(), image, repackage.NewPlan(layers))
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(result.Close)
+
+		Expect(repackage.ReadProvenance(result)).To(BeNil())
+		Expect(result.Digest()).To(Equal(imagetest.Must(image.Digest())))
+	})
+})
@@ -0,0 +1,181 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package repackage
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+)
+
+// Keys of the config labels and manifest annotations that record the
+// provenance of a repackaged image
+const (
+	ProvenanceSourceKey        = "io.github.homeport.forklift.source"
+	ProvenanceSourceImageIDKey = "io.github.homeport.forklift.source-image-id"
+	ProvenancePlanKey          = "io.github.homeport.forklift.plan"
+	ProvenanceLayersKey        = "io.github.homeport.forklift.layers"
+)
+
+// Provenance describes how a repackaged image was created
+type Provenance struct {
+	// Source is the manifest digest of the input image, it is not set if
+	// the digest is unknown (see WithSourceDigest)
+	Source *v1.Hash `json:"source,omitempty"`
+
+	// SourceImageID is the image ID, the config digest, of the input image
+	SourceImageID v1.Hash `json:"sourceImageID"`
+
+	// Plan is the executed plan in its textual form (without help section)
+	Plan string `json:"plan"`
+
+	// Layers maps each layer of the repackaged image to the input image
+	// layers it was created from
+	Layers []LayerProvenance `json:"layers"`
+}
+
+// LayerProvenance maps a layer to the layers it was created from
+type LayerProvenance struct {
+	DiffID  v1.Hash   `json:"diffID"`
+	Sources []v1.Hash `json:"sources"`
+}
+
+// ReadProvenance reads the provenance recorded in the config labels of a
+// repackaged image, it returns nil if the image has none
+func ReadProvenance(image v1.Image) (*Provenance, error) {
+	configFile, err := image.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	var labels = configFile.Config.Labels
+	if labels[ProvenanceLayersKey] == "" {
+		return nil, nil
+	}
+
+	var provenance = Provenance{Plan: labels[ProvenancePlanKey]}
+	if value := labels[ProvenanceSourceKey]; value != "" {
+		source, err := v1.NewHash(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid provenance source digest: %w", err)
+		}
+
+		provenance.Source = &source
+	}
+
+	if value := labels[ProvenanceSourceImageIDKey]; value != "" {
+		if provenance.SourceImageID, err = v1.NewHash(value); err != nil {
+			return nil, fmt.Errorf("invalid provenance source image ID: %w", err)
+		}
+	}
+
+	if err := json.Unmarshal([]byte(labels[ProvenanceLayersKey]), &provenance.Layers); err != nil {
+		return nil, fmt.Errorf("invalid provenance layer mapping: %w", err)
+	}
+
+	return &provenance, nil
+}
+
+// provenance creates the provenance for the repackaged image
+func provenance(input v1.Image, source *v1.Hash, plan Plan, stages []stage, image v1.Image) (*Provenance, error) {
+	imageID, err := input.ConfigName()
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := plan.text()
+	if err != nil {
+		return nil, err
+	}
+
+	configFile, err := image.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	var result = Provenance{Source: source, SourceImageID: imageID, Plan: string(text)}
+	var diffIDs = configFile.RootFS.DiffIDs
+	for _, stage := range stages {
+		var layers = stage.layers()
+		if len(layers) == 0 {
+			continue
+		}
+
+		if len(result.Layers) >= len(diffIDs) {
+			return nil, fmt.Errorf("repackaged image has less layers than expected")
+		}
+
+		var entry = LayerProvenance{DiffID: diffIDs[len(result.Layers)]}
+		for _, layer := range layers {
+			diffID, err := layer.DiffID()
+			if err != nil {
+				return nil, err
+			}
+
+			entry.Sources = append(entry.Sources, diffID)
+		}
+
+		result.Layers = append(result.Layers, entry)
+	}
+
+	return &result, nil
+}
+
+// apply records the provenance in the config labels, which are kept when
+// the image is written to a daemon, and the manifest annotations
+func (p *Provenance) apply(image v1.Image) (v1.Image, error) {
+	layers, err := json.Marshal(p.Layers)
+	if err != nil {
+		return nil, err
+	}
+
+	var values = map[string]string{
+		ProvenanceSourceImageIDKey: p.SourceImageID.String(),
+		ProvenancePlanKey:          p.Plan,
+		ProvenanceLayersKey:        string(layers),
+	}
+
+	if p.Source != nil {
+		values[ProvenanceSourceKey] = p.Source.String()
+	}
+
+	configFile, err := image.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	var config = *configFile.Config.DeepCopy()
+	if config.Labels == nil {
+		config.Labels = map[string]string{}
+	}
+
+	maps.Copy(config.Labels, values)
+
+	image, err = mutate.Config(image, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return mutate.Annotations(image, values).(v1.Image), nil
+}
@@ -22,14 +22,15 @@ package repackage
 
 import (
 	"compress/gzip"
+	"context"
 	"fmt"
+	"io"
 	"os"
-	"strings"
 
+	"github.com/homeport/forklift/pkg/misc"
 	"github.com/homeport/forklift/pkg/tar"
 
 	v1 "github.com/google/go-containerregistry/pkg/v1"
-	"github.com/google/go-containerregistry/pkg/v1/empty"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
 )
@@ -39,138 +40,347 @@ type Intention string
 const (
 	PICK  Intention = "pick"
 	FIXUP Intention = "fixup"
+	DROP  Intention = "drop"
 )
 
 type Action struct {
+	// Line of the action in the textual form of the plan (see ParsePlan)
+	Line int
+
 	OriginalIdx int
 	Intent      Intention
 	Layer       v1.Layer
 	History     *v1.History
+
+	// Locked actions belong to the base image and must be kept unchanged
+	Locked bool
 }
 
 type Plan []Action
 
-func Image(input v1.Image, plan Plan) (v1.Image, error) {
+// Option configures how an image is repackaged
+type Option func(*options)
+
+type options struct {
+	tempDir          string
+	compressionLevel int
+	observer         Observer
+	provenance       bool
+	source           *v1.Hash
+}
+
+// WithTempDir uses the given directory for temporary files and directories
+// instead of the default directory for temporary files
+func WithTempDir(dir string) Option {
+	return func(o *options) { o.tempDir = dir }
+}
+
+// WithCompressionLevel sets the gzip compression level of new layers, the
+// default is gzip.DefaultCompression
+func WithCompressionLevel(level int) Option {
+	return func(o *options) { o.compressionLevel = level }
+}
+
+// WithProvenance records the provenance of the repackaged image in its
+// config labels and manifest annotations (see Provenance)
+func WithProvenance() Option {
+	return func(o *options) { o.provenance = true }
+}
+
+// WithSourceDigest sets the manifest digest of the input image for the
+// provenance, it cannot be derived from the input image, since the Docker
+// daemon does not keep the manifest of an image
+func WithSourceDigest(digest v1.Hash) Option {
+	return func(o *options) { o.source = &digest }
+}
+
+// Result is the repackaged image, combined layers are backed by temporary
+// files, which are removed by Close once the image is no longer needed
+type Result struct {
+	v1.Image
+
+	// Provenance describes how the image was created, only set if it was
+	// requested using WithProvenance
+	Provenance *Provenance
+
+	scratch string
+}
+
+// Close removes all temporary files and directories of the result
+func (r *Result) Close() error {
+	if r == nil || r.scratch == "" {
+		return nil
+	}
+
+	return os.RemoveAll(r.scratch)
+}
+
+func newOptions(opts []Option) options {
+	var o = options{compressionLevel: gzip.DefaultCompression}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// Image creates a new image based on the input image, with its layers
+// repackaged as described by the plan. The result has to be closed after
+// it was written to remove temporary files. On error or cancellation of
+// the context, all temporary files are removed right away.
+func Image(ctx context.Context, input v1.Image, plan Plan, opts ...Option) (*Result, error) {
+	var o = newOptions(opts)
+
+	stages, err := plan.stages()
+	if err != nil {
+		return nil, err
+	}
+
 	configFile, err := input.ConfigFile()
 	if err != nil {
 		return nil, fmt.Errorf("failed to read image config file: %w", err)
 	}
 
-	// reset config details so that it can be used in a fresh image
-	configFile.RootFS.DiffIDs = []v1.Hash{}
-	configFile.History = []v1.History{}
+	image, err := misc.EmptyImage(configFile)
+	if err != nil {
+		return nil, err
+	}
 
-	// create a fresh empty image using the input image's config file
-	result, err := mutate.ConfigFile(empty.Image, configFile)
+	scratch, err := os.MkdirTemp(o.tempDir, "forklift-repackage")
 	if err != nil {
 		return nil, err
 	}
 
-	type repkgStage struct {
-		layer     *v1.Layer
-		history   *v1.History
-		directory *string
-		created   *v1.Time
-		createdBy []string
+	var result = &Result{scratch: scratch}
+	var fail = func(err error) (*Result, error) {
+		_ = result.Close()
+		return nil, err
 	}
 
-	var stage *repkgStage
+	for i, stage := range stages {
+		if err := ctx.Err(); err != nil {
+			return fail(err)
+		}
+
+		o.notify(StageStarted{Stage: i, Stages: len(stages), Sources: stage.sources()})
 
-	var flush = func() (err error) {
-		if stage == nil {
-			return nil
+		addendum, err := stage.addendum(ctx, i, scratch, o)
+		if err != nil {
+			return fail(err)
 		}
 
-		// TODO Remove me eventually
-		if stage.directory == nil && stage.layer == nil {
-			panic("invalid state")
+		image, err = mutate.Append(image, addendum)
+		if err != nil {
+			return fail(err)
 		}
 
-		if stage.directory != nil {
-			// TODO Remove temporary file at the end, defer won't work
-			tmpball, err := tar.Create(*stage.directory)
-			if err != nil {
-				return err
-			}
+		o.notify(AddendumAppended{Stage: i, EmptyLayer: addendum.Layer == nil, CreatedBy: addendum.History.CreatedBy})
+	}
 
-			stage.directory = nil
+	if o.provenance {
+		result.Provenance, err = provenance(input, o.source, plan, stages, image)
+		if err != nil {
+			return fail(err)
+		}
 
-			// TODO Make compression configurable
-			layer, err := tarball.LayerFromFile(tmpball.Name(), tarball.WithCompressionLevel(gzip.DefaultCompression))
-			if err != nil {
-				return err
-			}
+		image, err = result.Provenance.apply(image)
+		if err != nil {
+			return fail(err)
+		}
+	}
 
-			stage.layer = &layer
-			stage.history = &v1.History{
-				Author:    "forklift",
-				Comment:   "combined layers",
-				Created:   *stage.created,
-				CreatedBy: strings.Join(stage.createdBy, ", "),
-			}
+	// the digest requires reading all layers, so only compute it on demand
+	if o.observer != nil {
+		digest, err := image.Digest()
+		if err != nil {
+			return fail(err)
 		}
 
-		addendum := mutate.Addendum{Layer: *stage.layer}
+		o.notify(DigestComputed{Digest: digest})
+	}
+
+	result.Image = image
+	return result, nil
+}
 
-		if stage.history != nil {
-			addendum.History = *stage.history
+// addendum creates the addendum for the stage, a single pick is used as-is,
+// whereas a pick with fixups is combined into a new layer. Empty layer
+// entries (i.e. ENV or LABEL) only contribute their history and never
+// require a new layer blob. Temporary files are created in scratch.
+func (s stage) addendum(ctx context.Context, idx int, scratch string, o options) (mutate.Addendum, error) {
+	var head = s[0]
+	if len(s) == 1 {
+		addendum := mutate.Addendum{Layer: head.Layer}
+		if head.History != nil {
+			addendum.History = *head.History
 		}
 
-		result, err = mutate.Append(result, addendum)
-		stage = nil
-		return err
+		addendum.History.EmptyLayer = head.Layer == nil
+		return addendum, nil
 	}
 
-	for i := range plan {
-		var action = plan[i]
-		switch action.Intent {
+	var created v1.Time
+	for _, action := range s {
+		if action.History != nil {
+			created = action.History.Created
+		}
+	}
 
-		// TODO Write comment
-		case PICK:
-			if err := flush(); err != nil {
-				return nil, err
-			}
+	var layers = s.layers()
+	switch len(layers) {
+	case 0:
+		return mutate.Addendum{
+			History: v1.History{
+				Author:     "forklift",
+				Comment:    "combined history entries",
+				Created:    created,
+				CreatedBy:  s.createdBy(),
+				EmptyLayer: true,
+			},
+		}, nil
+
+	case 1:
+		return mutate.Addendum{
+			Layer: layers[0],
+			History: v1.History{
+				Author:    "forklift",
+				Comment:   "combined history entries",
+				Created:   created,
+				CreatedBy: s.createdBy(),
+			},
+		}, nil
+	}
 
-			stage = &repkgStage{
-				layer:   &action.Layer,
-				history: action.History,
-			}
+	dir, err := os.MkdirTemp(scratch, "fixup")
+	if err != nil {
+		return mutate.Addendum{}, err
+	}
 
-		// TODO Write comment
-		case FIXUP:
-			if action.Layer == nil {
-				return nil, errFixupOnEmptyLayer
-			}
+	// the extracted files are no longer needed once the tarball exists
+	defer func() { _ = os.RemoveAll(dir) }()
 
-			if stage.directory == nil {
-				dir, err := os.MkdirTemp("", "fixup")
-				if err != nil {
-					return nil, err
-				}
+	for _, action := range s {
+		if action.Layer == nil {
+			continue
+		}
 
-				stage.directory = &dir
+		n, err := extract(ctx, action.Layer, dir)
+		if err != nil {
+			return mutate.Addendum{}, layerError("extract", action, err)
+		}
 
-				if err := tar.ExtractLayer(*stage.layer, *stage.directory); err != nil {
-					return nil, err
-				}
+		o.notify(LayerExtracted{Stage: idx, Source: action.OriginalIdx, Bytes: n})
+	}
 
-				stage.createdBy = append(stage.createdBy, stage.history.CreatedBy)
-			}
+	// the tarball is removed with the scratch directory (see Result.Close)
+	tmpball, err := tar.Create(dir, tar.WithTempDir(scratch))
+	if err != nil {
+		return mutate.Addendum{}, layerError("combine", head, err)
+	}
 
-			if err := tar.ExtractLayer(action.Layer, *stage.directory); err != nil {
-				return nil, err
-			}
+	if err := tmpball.Close(); err != nil {
+		return mutate.Addendum{}, err
+	}
 
-			if action.History != nil {
-				stage.created = &action.History.Created
-				stage.createdBy = append(stage.createdBy, action.History.CreatedBy)
-			}
-		}
+	compressed, err := compress(ctx, tmpball.Name(), o.compressionLevel)
+	if err != nil {
+		return mutate.Addendum{}, layerError("compress", head, err)
 	}
 
-	if err := flush(); err != nil {
-		return nil, err
+	layer, err := tarball.LayerFromFile(compressed)
+	if err != nil {
+		return mutate.Addendum{}, err
 	}
 
-	return result, nil
+	digest, err := layer.Digest()
+	if err != nil {
+		return mutate.Addendum{}, err
+	}
+
+	size, err := layer.Size()
+	if err != nil {
+		return mutate.Addendum{}, err
+	}
+
+	o.notify(LayerCompressed{Stage: idx, Digest: digest, Size: size})
+
+	return mutate.Addendum{
+		Layer: layer,
+		History: v1.History{
+			Author:    "forklift",
+			Comment:   "combined layers",
+			Created:   created,
+			CreatedBy: s.createdBy(),
+		},
+	}, nil
+}
+
+// extract extracts the layer into the directory and returns the number of
+// compressed bytes read, it stops as soon as the context is cancelled
+func extract(ctx context.Context, layer v1.Layer, dir string) (int64, error) {
+	rc, err := layer.Compressed()
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() { _ = rc.Close() }()
+
+	var cr = &contextReader{ctx: ctx, r: rc}
+	err = tar.ExtractCompressed(cr, dir)
+	return cr.n, err
+}
+
+// compress compresses the tarball into a new file next to it and removes
+// the uncompressed tarball, compressing upfront (instead of when the image
+// is written) makes the layer digest available right away
+func compress(ctx context.Context, name string, level int) (string, error) {
+	src, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+
+	defer func() {
+		_ = src.Close()
+		_ = os.Remove(name)
+	}()
+
+	dst, err := os.Create(name + ".gz")
+	if err != nil {
+		return "", err
+	}
+
+	defer func() { _ = dst.Close() }()
+
+	gzw, err := gzip.NewWriterLevel(dst, level)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := io.Copy(gzw, &contextReader{ctx: ctx, r: src}); err != nil {
+		return "", err
+	}
+
+	if err := gzw.Close(); err != nil {
+		return "", err
+	}
+
+	return dst.Name(), dst.Close()
+}
+
+// contextReader fails reading once the context is cancelled and counts the
+// number of bytes read
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+	n   int64
+}
+
+func (cr *contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
 }
@@ -24,11 +24,12 @@ import (
 	"bytes"
 	"fmt"
 	"math/rand/v2"
+	"strings"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
-	"github.com/onsi/gomega/types"
+	gomegatypes "github.com/onsi/gomega/types"
 
 	"github.com/gonvenience/ytbx"
 	"github.com/homeport/dyff/pkg/dyff"
@@ -37,6 +38,10 @@ import (
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/daemon"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	randomimage "github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/types"
 )
 
 var lcs = []rune("abcdefghijklmnopqrstuvwxyz")
@@ -74,7 +79,39 @@ func pushDaemonImage(tag name.Tag, img v1.Image, options ...daemon.Option) {
 	Expect(err).ToNot(HaveOccurred(), response)
 }
 
-func BeImage(expected v1.Image) types.GomegaMatcher {
+func randomLayer() v1.Layer {
+	GinkgoHelper()
+
+	layer, err := randomimage.Layer(1024, types.DockerLayer)
+	Expect(err).ToNot(HaveOccurred())
+
+	return layer
+}
+
+// sampleImage creates an in-memory image with one history entry per given
+// created by string, entries starting with ENV are empty layers
+func sampleImage(createdBy ...string) v1.Image {
+	GinkgoHelper()
+
+	var image = empty.Image
+	for _, entry := range createdBy {
+		var addendum = mutate.Addendum{History: v1.History{CreatedBy: entry}}
+		if strings.HasPrefix(entry, "ENV") || strings.HasPrefix(entry, "LABEL") {
+			addendum.History.EmptyLayer = true
+
+		} else {
+			addendum.Layer = randomLayer()
+		}
+
+		var err error
+		image, err = mutate.Append(image, addendum)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	return image
+}
+
+func BeImage(expected v1.Image) gomegatypes.GomegaMatcher {
 	return &BeImageMatcher{expected: expected}
 }
 
@@ -21,6 +21,7 @@
 package repackage_test
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -51,8 +52,9 @@ var _ = Describe("Repackage", func() {
 			})
 		}
 
-		result, err := repackage.Image(sampleImage, plan)
+		result, err := repackage.Image(context.Background(), sampleImage, plan)
 		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(result.Close)
 
 		tag, err := name.NewTag("test:" + random(6))
 		Expect(err).ToNot(HaveOccurred())
@@ -61,8 +63,7 @@ var _ = Describe("Repackage", func() {
 		pushDaemonImage(tag, result)
 		defer pushDaemonImage(tag, empty.Image)
 
-		result = pullDaemonImage(tag.String())
-		Expect(result).To(BeImage(sampleImage))
+		Expect(pullDaemonImage(tag.String())).To(BeImage(sampleImage))
 	})
 
 	It("should combine layers into one", func() {
@@ -107,8 +108,9 @@ var _ = Describe("Repackage", func() {
 			)
 		}
 
-		result, err := repackage.Image(sampleImage, plan)
+		result, err := repackage.Image(context.Background(), sampleImage, plan)
 		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(result.Close)
 
 		tag, err := name.NewTag("test:" + random(6))
 		Expect(err).ToNot(HaveOccurred())
@@ -117,9 +119,7 @@ var _ = Describe("Repackage", func() {
 		pushDaemonImage(tag, result)
 		defer pushDaemonImage(tag, empty.Image)
 
-		result = pullDaemonImage(tag.String())
-
-		layers, err = misc.Layers(result)
+		layers, err = misc.Layers(pullDaemonImage(tag.String()))
 		Expect(err).ToNot(HaveOccurred())
 		Expect(layers).To(HaveLen(4))
 	})
@@ -0,0 +1,146 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Rule describes a kind of secret by a regular expression
+type Rule struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+	Regex       string `yaml:"regex"`
+}
+
+// DefaultRules returns the built-in rules for common private keys, tokens
+// and passwords
+func DefaultRules() []Rule {
+	return []Rule{
+		{
+			Name:        "private-key",
+			Description: "PEM encoded private key",
+			Regex:       `-----BEGIN ((RSA|DSA|EC|OPENSSH|PGP|ENCRYPTED) )?PRIVATE KEY( BLOCK)?-----`,
+		},
+		{
+			Name:        "aws-access-key-id",
+			Description: "AWS access key ID",
+			Regex:       `\b(AKIA|ASIA)[0-9A-Z]{16}\b`,
+		},
+		{
+			Name:        "github-token",
+			Description: "GitHub personal access, OAuth, or app token",
+			Regex:       `\bgh[pousr]_[A-Za-z0-9]{36,}\b`,
+		},
+		{
+			Name:        "gitlab-token",
+			Description: "GitLab personal access token",
+			Regex:       `\bglpat-[A-Za-z0-9_-]{20,}\b`,
+		},
+		{
+			Name:        "slack-token",
+			Description: "Slack token",
+			Regex:       `\bxox[abprs]-[A-Za-z0-9-]{10,}\b`,
+		},
+		{
+			Name:        "google-api-key",
+			Description: "Google API key",
+			Regex:       `\bAIza[0-9A-Za-z_-]{35}\b`,
+		},
+		{
+			Name:        "docker-config-auth",
+			Description: "Registry credentials in a Docker config file",
+			Regex:       `"auth"\s*:\s*"[A-Za-z0-9+/=]{16,}"`,
+		},
+		{
+			Name:        "url-credentials",
+			Description: "User and password in a URL",
+			Regex:       `\b[a-zA-Z][a-zA-Z0-9+.-]*://[^/\s:@"']+:[^/\s:@"']+@`,
+		},
+		{
+			Name:        "password-assignment",
+			Description: "Assignment of a password, secret, or token value",
+			Regex:       `(?i)\b(password|passwd|secret|api[_-]?key|access[_-]?token|auth[_-]?token)\b["']?\s*[:=]\s*["']?[^\s"'$]{8,}`,
+		},
+	}
+}
+
+// LoadRules reads rules from a YAML file, which has a top-level rules list
+func LoadRules(filename string) ([]Rule, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Rules []Rule `yaml:"rules"`
+	}
+
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse rules file %s: %w", filename, err)
+	}
+
+	return file.Rules, nil
+}
+
+// MergeRules adds the additional rules to the given ones, an additional rule
+// replaces an existing rule with the same name
+func MergeRules(rules []Rule, additional ...Rule) []Rule {
+	var result = slices.Clone(rules)
+	for _, rule := range additional {
+		idx := slices.IndexFunc(result, func(r Rule) bool { return r.Name == rule.Name })
+		if idx >= 0 {
+			result[idx] = rule
+		} else {
+			result = append(result, rule)
+		}
+	}
+
+	return result
+}
+
+type compiledRule struct {
+	Rule
+	regex *regexp.Regexp
+}
+
+func compile(rules []Rule) ([]compiledRule, error) {
+	var result = make([]compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("rule with regex %q has no name", rule.Regex)
+		}
+
+		regex, err := regexp.Compile(rule.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile regex of rule %s: %w", rule.Name, err)
+		}
+
+		result = append(result, compiledRule{Rule: rule, regex: regex})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,269 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package secrets
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/homeport/forklift/pkg/misc"
+	forklifttar "github.com/homeport/forklift/pkg/tar"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// DefaultMaxFileSize is the size limit for files to be scanned
+const DefaultMaxFileSize = 10 << 20
+
+// ConfigLayer is the layer index used for findings in the image config
+const ConfigLayer = -1
+
+// Finding is a potential secret found in the image
+type Finding struct {
+	// Rule is the name of the matching rule
+	Rule string `json:"rule"`
+
+	// Layer is the index of the layer or history entry (see misc.Layers), or
+	// ConfigLayer for findings in the image config
+	Layer int `json:"layer"`
+
+	// Path is the file path, or the name of the config or history field
+	Path string `json:"path"`
+
+	// Line is the line number of the match in the file or field
+	Line int `json:"line"`
+
+	// Excerpt is the redacted matching text
+	Excerpt string `json:"excerpt"`
+
+	// Deleted indicates that the file is deleted by a later layer, so that
+	// it is not visible in a container, but still part of the layer blob
+	Deleted bool `json:"deleted"`
+}
+
+// Skipped is a file that was not scanned, because it exceeds the size limit
+type Skipped struct {
+	// Layer is the index of the layer (see misc.Layers)
+	Layer int `json:"layer"`
+
+	// Path is the file path
+	Path string `json:"path"`
+
+	// Size is the file size in bytes
+	Size int64 `json:"size"`
+}
+
+// Report is the result of an image scan
+type Report struct {
+	Findings []Finding `json:"findings"`
+	Skipped  []Skipped `json:"skipped"`
+}
+
+// Scanner scans images for secrets using a set of rules
+type Scanner struct {
+	rules []compiledRule
+
+	// MaxFileSize is the size limit for files to be scanned, larger files
+	// are skipped and listed in the report
+	MaxFileSize int64
+}
+
+// NewScanner creates a scanner for the given rules
+func NewScanner(rules []Rule) (*Scanner, error) {
+	compiled, err := compile(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Scanner{rules: compiled, MaxFileSize: DefaultMaxFileSize}, nil
+}
+
+// whiteout is a whiteout entry of a layer
+type whiteout struct {
+	target string
+	opaque bool
+}
+
+func (w whiteout) hides(name string) bool {
+	if w.opaque {
+		return strings.HasPrefix(name, w.target+"/")
+	}
+
+	return name == w.target || strings.HasPrefix(name, w.target+"/")
+}
+
+// Image scans the image config, all history entries, and the files in all
+// layers. Each layer blob is scanned on its own, so files that are deleted
+// in a later layer are found, too.
+func (s *Scanner) Image(image v1.Image) (*Report, error) {
+	configFile, err := image.ConfigFile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image config file: %w", err)
+	}
+
+	// empty lists instead of nil, so that the report renders as [] and not null
+	var findings = []Finding{}
+	var skipped = []Skipped{}
+
+	var config = configFile.Config
+	findings = append(findings, s.text(ConfigLayer, "Env", strings.Join(config.Env, "\n"))...)
+	findings = append(findings, s.text(ConfigLayer, "Entrypoint", strings.Join(config.Entrypoint, " "))...)
+	findings = append(findings, s.text(ConfigLayer, "Cmd", strings.Join(config.Cmd, " "))...)
+	for key, value := range config.Labels {
+		findings = append(findings, s.text(ConfigLayer, "Labels", key+"="+value)...)
+	}
+
+	for i, entry := range configFile.History {
+		findings = append(findings, s.text(i, "CreatedBy", entry.CreatedBy)...)
+	}
+
+	layers, err := layers(image)
+	if err != nil {
+		return nil, err
+	}
+
+	var whiteouts = make(map[int][]whiteout, len(layers))
+	for idx, layer := range layers {
+		if layer.Layer == nil {
+			continue
+		}
+
+		err := forklifttar.Walk(layer.Layer, func(header *tar.Header, r io.Reader) error {
+			var name = forklifttar.Normalize(header.Name)
+			var dir, base = path.Split(name)
+
+			switch {
+			case base == forklifttar.WhiteoutOpaque:
+				whiteouts[idx] = append(whiteouts[idx], whiteout{target: strings.TrimSuffix(dir, "/"), opaque: true})
+				return nil
+
+			case strings.HasPrefix(base, forklifttar.WhiteoutPrefix):
+				whiteouts[idx] = append(whiteouts[idx], whiteout{target: dir + strings.TrimPrefix(base, forklifttar.WhiteoutPrefix)})
+				return nil
+
+			case header.Typeflag != tar.TypeReg:
+				return nil
+
+			case header.Size > s.MaxFileSize:
+				skipped = append(skipped, Skipped{Layer: idx, Path: "/" + name, Size: header.Size})
+				return nil
+			}
+
+			data, err := io.ReadAll(r)
+			if err != nil {
+				return err
+			}
+
+			// skip binary files
+			if bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0 {
+				return nil
+			}
+
+			findings = append(findings, s.text(idx, "/"+name, string(data))...)
+			return nil
+		})
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan layer %d: %w", idx, err)
+		}
+	}
+
+	for i := range findings {
+		var finding = &findings[i]
+		if finding.Layer == ConfigLayer || !strings.HasPrefix(finding.Path, "/") {
+			continue
+		}
+
+		var name = strings.TrimPrefix(finding.Path, "/")
+		for idx, entries := range whiteouts {
+			if idx <= finding.Layer {
+				continue
+			}
+
+			for _, entry := range entries {
+				if entry.hides(name) {
+					finding.Deleted = true
+				}
+			}
+		}
+	}
+
+	return &Report{Findings: findings, Skipped: skipped}, nil
+}
+
+// text scans the text line by line
+func (s *Scanner) text(layer int, source string, text string) []Finding {
+	var findings []Finding
+	for i, line := range strings.Split(text, "\n") {
+		for _, rule := range s.rules {
+			if match := rule.regex.FindString(line); match != "" {
+				findings = append(findings, Finding{
+					Rule:    rule.Name,
+					Layer:   layer,
+					Path:    source,
+					Line:    i + 1,
+					Excerpt: redact(match),
+				})
+			}
+		}
+	}
+
+	return findings
+}
+
+// layers returns the layers of the image, where images without history get
+// one entry per layer
+func layers(image v1.Image) ([]misc.Layer, error) {
+	configFile, err := image.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(configFile.History) > 0 {
+		return misc.Layers(image)
+	}
+
+	layers, err := image.Layers()
+	if err != nil {
+		return nil, err
+	}
+
+	var result = make([]misc.Layer, len(layers))
+	for i := range layers {
+		result[i] = misc.Layer{Layer: layers[i], LayerIdx: &i}
+	}
+
+	return result, nil
+}
+
+// redact keeps the beginning of the match to identify it
+func redact(match string) string {
+	var runes = []rune(match)
+	if len(runes) <= 8 {
+		return strings.Repeat("*", len(runes))
+	}
+
+	return string(runes[:6]) + strings.Repeat("*", min(len(runes)-6, 16))
+}
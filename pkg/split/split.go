@@ -0,0 +1,266 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package split
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/homeport/forklift/pkg/misc"
+	forklifttar "github.com/homeport/forklift/pkg/tar"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+)
+
+// Part describes one of the layers a layer is split into
+type Part struct {
+	// Patterns that select the entries of the part, none for the part with
+	// the remaining entries
+	Patterns []string
+
+	// Entries is the number of tarball entries in the part
+	Entries int
+}
+
+// Option configures how a layer is split
+type Option func(*options)
+
+type options struct {
+	tempDir          string
+	compressionLevel int
+}
+
+// WithTempDir creates the temporary tarballs in the given directory instead
+// of the default directory for temporary files
+func WithTempDir(dir string) Option {
+	return func(o *options) { o.tempDir = dir }
+}
+
+// WithCompressionLevel sets the gzip compression level of new layers, the
+// default is gzip.DefaultCompression
+func WithCompressionLevel(level int) Option {
+	return func(o *options) { o.compressionLevel = level }
+}
+
+func newOptions(opts []Option) options {
+	var o = options{compressionLevel: gzip.DefaultCompression}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// Image splits the layer of the history entry idx (see misc.Layers) into
+// one layer per group of patterns (see tar.Match), followed by one layer
+// with the remaining entries. Each entry goes into the first group with a
+// matching pattern, whiteouts go into the group of the path they remove and
+// hardlinks go into the group of their target.
+// Opaque whiteouts go into the bottom-most new layer, so that they cannot
+// hide content of the other new layers. Groups without entries are left out.
+// The result has to be closed after it was written to remove temporary files.
+func Image(input v1.Image, idx int, groups [][]string, opts ...Option) (*misc.TempImage, []Part, error) {
+	var o = newOptions(opts)
+	for _, patterns := range groups {
+		if len(patterns) == 0 {
+			return nil, nil, errors.New("pattern group must not be empty")
+		}
+
+		if err := forklifttar.ValidatePatterns(patterns); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	layers, err := misc.Layers(input)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if idx < 0 || idx >= len(layers) {
+		return nil, nil, fmt.Errorf("layer index %d is out of range, image has %d layers", idx, len(layers))
+	}
+
+	var layer = layers[idx]
+	if layer.Layer == nil {
+		return nil, nil, fmt.Errorf("layer %d is an empty layer and cannot be split", idx)
+	}
+
+	// the last part holds all remaining entries
+	var parts = make([]Part, len(groups)+1)
+	for i := range groups {
+		parts[i].Patterns = groups[i]
+	}
+
+	// the parts of the entries, so that hardlinks can follow their target,
+	// which precedes them in the tarball
+	var assigned = map[string]int{}
+
+	var partOf = func(header *tar.Header) (int, error) {
+		if header.Typeflag == tar.TypeLink {
+			if i, ok := assigned[forklifttar.Normalize(header.Linkname)]; ok {
+				return i, nil
+			}
+		}
+
+		var name = forklifttar.Normalize(header.Name)
+		var dir, base = path.Split(name)
+		if strings.HasPrefix(base, forklifttar.WhiteoutPrefix) && base != forklifttar.WhiteoutOpaque {
+			name = dir + strings.TrimPrefix(base, forklifttar.WhiteoutPrefix)
+		}
+
+		for i, patterns := range groups {
+			matched, err := forklifttar.MatchAny(patterns, name)
+			if err != nil || matched {
+				return i, err
+			}
+		}
+
+		return len(groups), nil
+	}
+
+	var opaque int
+	err = forklifttar.Walk(layer.Layer, func(header *tar.Header, _ io.Reader) error {
+		if path.Base(forklifttar.Normalize(header.Name)) == forklifttar.WhiteoutOpaque {
+			opaque++
+			return nil
+		}
+
+		i, err := partOf(header)
+		if err != nil {
+			return err
+		}
+
+		assigned[forklifttar.Normalize(header.Name)] = i
+		parts[i].Entries++
+		return nil
+	})
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read layer %d: %w", idx, err)
+	}
+
+	var bottom = -1
+	var nonEmpty int
+	for i := range parts {
+		if parts[i].Entries > 0 {
+			nonEmpty++
+			if bottom < 0 {
+				bottom = i
+			}
+		}
+	}
+
+	if nonEmpty < 2 {
+		return nil, nil, fmt.Errorf("layer %d would not be split, all entries end up in one layer", idx)
+	}
+
+	parts[bottom].Entries += opaque
+
+	var temp = &misc.TempImage{}
+	var fail = func(err error) (*misc.TempImage, []Part, error) {
+		_ = temp.Close()
+		return nil, nil, err
+	}
+
+	var addenda []mutate.Addendum
+	var result []Part
+	for i := range parts {
+		if parts[i].Entries == 0 {
+			continue
+		}
+
+		tmpball, _, err := forklifttar.Filter(layer.Layer, func(header *tar.Header) (bool, error) {
+			if path.Base(forklifttar.Normalize(header.Name)) == forklifttar.WhiteoutOpaque {
+				return i == bottom, nil
+			}
+
+			part, err := partOf(header)
+			return part == i, err
+		}, forklifttar.WithTempDir(o.tempDir))
+
+		if err != nil {
+			return fail(fmt.Errorf("failed to split layer %d: %w", idx, err))
+		}
+
+		temp.Track(tmpball)
+
+		newLayer, err := tarball.LayerFromFile(tmpball.Name(), tarball.WithCompressionLevel(o.compressionLevel))
+		if err != nil {
+			return fail(err)
+		}
+
+		var comment = fmt.Sprintf("split from layer %d, remaining entries", idx)
+		if len(parts[i].Patterns) > 0 {
+			comment = fmt.Sprintf("split from layer %d, entries matching %s", idx, strings.Join(parts[i].Patterns, ", "))
+		}
+
+		addenda = append(addenda, mutate.Addendum{
+			Layer: newLayer,
+			History: v1.History{
+				Author:    "forklift",
+				Comment:   comment,
+				Created:   layer.History.Created,
+				CreatedBy: layer.History.CreatedBy,
+			},
+		})
+
+		result = append(result, parts[i])
+	}
+
+	if temp.Image, err = replace(input, layers, idx, addenda); err != nil {
+		return fail(err)
+	}
+
+	return temp, result, nil
+}
+
+// replace creates a new image where the history entry idx is replaced with
+// the given addenda
+func replace(input v1.Image, layers []misc.Layer, idx int, replacement []mutate.Addendum) (v1.Image, error) {
+	configFile, err := input.ConfigFile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image config file: %w", err)
+	}
+
+	result, err := misc.EmptyImage(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var addenda = make([]mutate.Addendum, 0, len(layers)+len(replacement))
+	for i, layer := range layers {
+		if i == idx {
+			addenda = append(addenda, replacement...)
+			continue
+		}
+
+		addenda = append(addenda, mutate.Addendum{Layer: layer.Layer, History: *layer.History})
+	}
+
+	return mutate.Append(result, addenda...)
+}
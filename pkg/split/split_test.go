@@ -0,0 +1,123 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package split_test
+
+import (
+	"archive/tar"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/homeport/forklift/internal/imagetest"
+	"github.com/homeport/forklift/pkg/split"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+)
+
+var _ = Describe("Split", func() {
+	var input = func() v1.Image {
+		return imagetest.Image(
+			[]string{"etc/passwd"},
+			[]string{"usr/lib/libfoo.so", "app/main", "usr/lib/.wh.libbar.so", "app/.wh..wh..opq", "app/config"},
+		)
+	}
+
+	It("should split a layer into one layer per pattern group plus the remaining entries", func() {
+		result, parts, err := split.Image(input(), 1, [][]string{{"usr/lib/**"}, {"app/main"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(parts).To(HaveLen(3))
+		Expect(parts[2].Patterns).To(BeEmpty())
+
+		layers, err := result.Layers()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(layers).To(HaveLen(4))
+		Expect(imagetest.Files(layers[0])).To(Equal([]string{"etc/passwd"}))
+		Expect(imagetest.Files(layers[1])).To(Equal([]string{"usr/lib/libfoo.so", "usr/lib/.wh.libbar.so", "app/.wh..wh..opq"}))
+		Expect(imagetest.Files(layers[2])).To(Equal([]string{"app/main"}))
+		Expect(imagetest.Files(layers[3])).To(Equal([]string{"app/config"}))
+
+		configFile, err := result.ConfigFile()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(configFile.History).To(HaveLen(4))
+		for _, entry := range configFile.History[1:] {
+			Expect(entry.CreatedBy).To(Equal("layer b"))
+		}
+
+		Expect(configFile.History[1].Comment).To(ContainSubstring("usr/lib/**"))
+		Expect(configFile.History[3].Comment).To(ContainSubstring("remaining entries"))
+	})
+
+	It("should remove temporary files when the result is closed", func() {
+		var tmp = GinkgoT().TempDir()
+		GinkgoT().Setenv("TMPDIR", tmp)
+
+		result, _, err := split.Image(input(), 1, [][]string{{"usr/lib/**"}})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(os.ReadDir(tmp)).ToNot(BeEmpty())
+		Expect(result.Close()).To(Succeed())
+		Expect(os.ReadDir(tmp)).To(BeEmpty())
+	})
+
+	It("should keep hardlinks in the layer of their target", func() {
+		image, err := mutate.AppendLayers(empty.Image, imagetest.LayerOf(
+			&tar.Header{Typeflag: tar.TypeReg, Name: "usr/lib/libfoo.so.1", Mode: 0644},
+			&tar.Header{Typeflag: tar.TypeLink, Name: "app/libfoo.so", Linkname: "usr/lib/libfoo.so.1"},
+			&tar.Header{Typeflag: tar.TypeReg, Name: "app/main", Mode: 0755},
+		))
+		Expect(err).ToNot(HaveOccurred())
+
+		result, parts, err := split.Image(image, 0, [][]string{{"usr/lib/**"}})
+		Expect(err).ToNot(HaveOccurred())
+		defer func() { _ = result.Close() }()
+
+		Expect(parts).To(HaveLen(2))
+		Expect(parts[0].Entries).To(Equal(2))
+
+		layers, err := result.Layers()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(imagetest.Files(layers[0])).To(Equal([]string{"usr/lib/libfoo.so.1", "app/libfoo.so"}))
+		Expect(imagetest.Files(layers[1])).To(Equal([]string{"app/main"}))
+	})
+
+	It("should leave out groups without entries", func() {
+		result, parts, err := split.Image(input(), 1, [][]string{{"opt/**"}, {"app/**"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(parts).To(HaveLen(2))
+
+		layers, err := result.Layers()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(layers).To(HaveLen(3))
+	})
+
+	It("should fail if the layer would not be split", func() {
+		_, _, err := split.Image(input(), 0, [][]string{{"etc/**"}})
+		Expect(err).To(MatchError(ContainSubstring("would not be split")))
+	})
+
+	It("should fail on out of range layer indexes", func() {
+		_, _, err := split.Image(input(), 2, [][]string{{"etc/**"}})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -21,6 +21,8 @@
 package tar
 
 import (
+	"archive/tar"
+	"errors"
 	"os"
 	"path/filepath"
 )
@@ -41,3 +43,19 @@ func followSymLink(path string) (string, os.FileInfo, error) {
 	info, err := os.Stat(deref)
 	return deref, info, err
 }
+
+// tempTarball creates a temporary tarball in the configured directory using
+// the write function, the tarball is removed again if writing fails
+func tempTarball(o options, write func(tw *tar.Writer) error) (*os.File, error) {
+	target, err := os.CreateTemp(o.tempDir, "tarball")
+	if err != nil {
+		return nil, err
+	}
+
+	var tw = tar.NewWriter(target)
+	if err := errors.Join(write(tw), tw.Close()); err != nil {
+		return nil, errors.Join(err, target.Close(), os.Remove(target.Name()))
+	}
+
+	return target, nil
+}
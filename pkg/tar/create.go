@@ -22,26 +22,131 @@ package tar
 
 import (
 	"archive/tar"
-	"fmt"
 	"io"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 )
 
-func Create(directory string) (*os.File, error) {
-	target, err := os.CreateTemp("", "tarball")
-	if err != nil {
-		return nil, err
+// Option configures how a tarball is created
+type Option func(*options)
+
+type options struct {
+	tempDir  string
+	prefix   string
+	uid      *int
+	gid      *int
+	mode     *fs.FileMode
+	existing func(string) bool
+}
+
+// newOptions applies the given options to the defaults
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
 	}
 
-	tw := tar.NewWriter(target)
-	defer func() {
-		_ = tw.Flush()
-		_ = tw.Close()
-	}()
+	return o
+}
+
+// WithTempDir creates the tarball in the given directory instead of the
+// default directory for temporary files
+func WithTempDir(dir string) Option {
+	return func(o *options) { o.tempDir = dir }
+}
+
+// WithPrefix places all entries under the given path inside the tarball
+func WithPrefix(prefix string) Option {
+	return func(o *options) { o.prefix = prefix }
+}
+
+// WithOwner overrides the user and group ID of all entries
+func WithOwner(uid, gid int) Option {
+	return func(o *options) { o.uid, o.gid = &uid, &gid }
+}
+
+// WithMode overrides the permission bits of all entries except symbolic links
+func WithMode(mode fs.FileMode) Option {
+	return func(o *options) { o.mode = &mode }
+}
+
+// WithExisting skips the entries of parent directories that already exist,
+// for example in the lower layers of an image, so that they keep their
+// metadata, the function is called with the path inside the tarball
+func WithExisting(exists func(name string) bool) Option {
+	return func(o *options) { o.existing = exists }
+}
+
+// Entry maps a local file or directory to a path inside the tarball
+type Entry struct {
+	Source string
+	Target string
+}
+
+// Create creates a temporary tarball with the contents of the directory, the
+// caller has to remove it, on error no tarball is left behind
+func Create(directory string, opts ...Option) (*os.File, error) {
+	return CreateFrom([]Entry{{Source: directory}}, opts...)
+}
+
+// CreateFrom creates a temporary tarball with the given files and directories,
+// parent directories of the entry targets are created as required, the owner
+// and mode overrides only apply to the given files and directories
+func CreateFrom(entries []Entry, opts ...Option) (*os.File, error) {
+	var o = newOptions(opts)
+	return tempTarball(o, func(tw *tar.Writer) error {
+		var written = map[string]struct{}{}
+		for _, entry := range entries {
+			var base = strings.TrimLeft(path.Join(o.prefix, filepath.ToSlash(entry.Target)), "/")
+			if err := writeParents(tw, base, written, o); err != nil {
+				return err
+			}
 
-	return target, filepath.WalkDir(directory, func(path string, d fs.DirEntry, err error) error {
+			if err := add(tw, entry.Source, base, written, o); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// writeParents writes directory entries for all parent directories of name
+func writeParents(tw *tar.Writer, name string, written map[string]struct{}, o options) error {
+	var dir = path.Dir(name)
+	if dir == "." || dir == "/" {
+		return nil
+	}
+
+	if _, ok := written[dir]; ok {
+		return nil
+	}
+
+	if o.exists(dir) {
+		written[dir] = struct{}{}
+		return nil
+	}
+
+	if err := writeParents(tw, dir, written, o); err != nil {
+		return err
+	}
+
+	var header = &tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     dir,
+		Mode:     0755,
+	}
+
+	written[dir] = struct{}{}
+	return tw.WriteHeader(header)
+}
+
+// add writes the file or directory (recursively) using name as the base
+func add(tw *tar.Writer, source string, name string, written map[string]struct{}, o options) error {
+	return filepath.WalkDir(source, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
@@ -51,21 +156,32 @@ func Create(directory string) (*os.File, error) {
 			return err
 		}
 
-		header, err := tar.FileInfoHeader(info, path)
+		rel, err := filepath.Rel(source, path)
 		if err != nil {
 			return err
 		}
 
-		header.Name, err = filepath.Rel(directory, path)
+		var entryName = filepath.ToSlash(filepath.Join(name, rel))
+		if entryName == "." || (path == source && info.IsDir() && o.exists(entryName)) {
+			// no entry for the root directory itself, or an existing directory
+			return nil
+		}
+
+		header, err := tar.FileInfoHeader(info, path)
 		if err != nil {
 			return err
 		}
 
+		header.Name = entryName
+
 		switch {
 		case info.Mode().IsDir():
+			o.apply(header)
+			written[entryName] = struct{}{}
 			return tw.WriteHeader(header)
 
 		case info.Mode().IsRegular():
+			o.apply(header)
 			if err := tw.WriteHeader(header); err != nil {
 				return err
 			}
@@ -83,11 +199,9 @@ func Create(directory string) (*os.File, error) {
 				return err
 			}
 
-			header.Name, err = filepath.Rel(directory, path)
-			if err != nil {
-				return err
-			}
+			header.Name = entryName
 
+			o.apply(header)
 			if err := tw.WriteHeader(header); err != nil {
 				return err
 			}
@@ -95,11 +209,31 @@ func Create(directory string) (*os.File, error) {
 			return write(tw, deref)
 
 		default:
-			return fmt.Errorf("unsupported file type: %s", path)
+			return &UnsupportedEntryError{Path: entryName, Type: fileType(info.Mode())}
 		}
 	})
 }
 
+// exists returns whether the directory already exists
+func (o options) exists(name string) bool {
+	return o.existing != nil && o.existing(name)
+}
+
+// apply overrides the header details as configured
+func (o options) apply(header *tar.Header) {
+	if o.uid != nil {
+		header.Uid, header.Uname = *o.uid, ""
+	}
+
+	if o.gid != nil {
+		header.Gid, header.Gname = *o.gid, ""
+	}
+
+	if o.mode != nil && header.Typeflag != tar.TypeSymlink {
+		header.Mode = int64(o.mode.Perm())
+	}
+}
+
 func write(w io.Writer, path string) error {
 	file, err := os.Open(path)
 	if err != nil {
@@ -0,0 +1,125 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tar_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/homeport/forklift/pkg/tar"
+)
+
+var _ = Describe("Create", func() {
+	var dir string
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		Expect(os.MkdirAll(filepath.Join(dir, "conf.d"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "conf.d", "app.conf"), []byte("foo"), 0600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "ca.pem"), []byte("bar"), 0600)).To(Succeed())
+	})
+
+	It("should create a tarball with the directory contents", func() {
+		file, err := tar.Create(dir)
+		Expect(err).ToNot(HaveOccurred())
+		defer func() { _ = os.Remove(file.Name()) }()
+
+		Expect(headers(file)).To(HaveKey("conf.d"))
+		Expect(headers(file)).To(HaveKey("conf.d/app.conf"))
+		Expect(headers(file)).To(HaveKey("ca.pem"))
+	})
+
+	It("should support a destination prefix and ownership overrides", func() {
+		file, err := tar.CreateFrom(
+			[]tar.Entry{
+				{Source: filepath.Join(dir, "ca.pem"), Target: "/etc/ssl/certs/ca.pem"},
+				{Source: filepath.Join(dir, "conf.d"), Target: "etc/app"},
+			},
+			tar.WithPrefix("/opt"),
+			tar.WithOwner(1000, 2000),
+			tar.WithMode(0644),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer func() { _ = os.Remove(file.Name()) }()
+
+		var result = headers(file)
+		Expect(result).To(HaveKey("opt"))
+		Expect(result).To(HaveKey("opt/etc/ssl/certs"))
+		Expect(result).To(HaveKey("opt/etc/app/app.conf"))
+
+		header := result["opt/etc/ssl/certs/ca.pem"]
+		Expect(header).ToNot(BeNil())
+		Expect(header.Uid).To(Equal(1000))
+		Expect(header.Gid).To(Equal(2000))
+		Expect(header.Mode).To(Equal(int64(0644)))
+	})
+
+	It("should not apply ownership overrides to parent directories", func() {
+		file, err := tar.CreateFrom(
+			[]tar.Entry{{Source: filepath.Join(dir, "ca.pem"), Target: "/etc/ssl/certs/ca.pem"}},
+			tar.WithOwner(1000, 2000),
+			tar.WithMode(0644),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer func() { _ = os.Remove(file.Name()) }()
+
+		header := headers(file)["etc/ssl"]
+		Expect(header).ToNot(BeNil())
+		Expect(header.Uid).To(Equal(0))
+		Expect(header.Mode).To(Equal(int64(0755)))
+	})
+
+	It("should skip existing parent directories", func() {
+		file, err := tar.CreateFrom(
+			[]tar.Entry{
+				{Source: filepath.Join(dir, "ca.pem"), Target: "/etc/ssl/certs/ca.pem"},
+				{Source: filepath.Join(dir, "conf.d"), Target: "/etc"},
+			},
+			tar.WithExisting(func(name string) bool { return name == "etc" || name == "etc/ssl" }),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer func() { _ = os.Remove(file.Name()) }()
+
+		var result = headers(file)
+		Expect(result).ToNot(HaveKey("etc"))
+		Expect(result).ToNot(HaveKey("etc/ssl"))
+		Expect(result).To(HaveKey("etc/ssl/certs"))
+		Expect(result).To(HaveKey("etc/app.conf"))
+	})
+
+	It("should fail with a typed error on unsupported file types", func() {
+		Expect(syscall.Mkfifo(filepath.Join(dir, "pipe"), 0600)).To(Succeed())
+
+		var tmp = GinkgoT().TempDir()
+		_, err := tar.Create(dir, tar.WithTempDir(tmp))
+		Expect(os.ReadDir(tmp)).To(BeEmpty())
+
+		var entryErr *tar.UnsupportedEntryError
+		Expect(errors.As(err, &entryErr)).To(BeTrue())
+		Expect(entryErr.Path).To(Equal("pipe"))
+		Expect(entryErr.Type).To(Equal("named pipe"))
+	})
+})
@@ -0,0 +1,86 @@
+// Copyright © 2024 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tar
+
+import (
+	"archive/tar"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// UnsupportedEntryError is returned for files that cannot be added to a
+// tarball, i.e. devices, named pipes, or sockets, or that cannot be
+// extracted from one, i.e. links, devices, or whiteouts; the path is the
+// path inside of the tarball
+type UnsupportedEntryError struct {
+	Path string
+	Type string
+}
+
+func (e *UnsupportedEntryError) Error() string {
+	return fmt.Sprintf("unsupported file type %s: %s", e.Type, e.Path)
+}
+
+func fileType(mode fs.FileMode) string {
+	switch {
+	case mode&fs.ModeDevice != 0 && mode&fs.ModeCharDevice != 0:
+		return "character device"
+
+	case mode&fs.ModeDevice != 0:
+		return "device"
+
+	case mode&fs.ModeNamedPipe != 0:
+		return "named pipe"
+
+	case mode&fs.ModeSocket != 0:
+		return "socket"
+
+	default:
+		return "irregular file"
+	}
+}
+
+func headerType(header *tar.Header) string {
+	switch {
+	case header.Typeflag == tar.TypeSymlink:
+		return "symbolic link"
+
+	case header.Typeflag == tar.TypeLink:
+		return "hard link"
+
+	case header.Typeflag == tar.TypeChar:
+		return "character device"
+
+	case header.Typeflag == tar.TypeBlock:
+		return "device"
+
+	case header.Typeflag == tar.TypeFifo:
+		return "named pipe"
+
+	case strings.HasPrefix(path.Base(header.Name), ".wh."):
+		return "whiteout"
+
+	default:
+		return ""
+	}
+}
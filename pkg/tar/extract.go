@@ -25,7 +25,9 @@ import (
 	"compress/gzip"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 )
@@ -66,6 +68,12 @@ func ExtractCompressed(r io.Reader, dst string) error {
 			continue
 		}
 
+		// links, devices, and whiteouts cannot be restored in a plain
+		// directory, fail instead of silently dropping them
+		if kind := headerType(header); kind != "" {
+			return &UnsupportedEntryError{Path: strings.TrimPrefix(path.Clean("/"+header.Name), "/"), Type: kind}
+		}
+
 		// the target location where the dir/file should be created
 		target := filepath.Join(dst, header.Name)
 
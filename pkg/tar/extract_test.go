@@ -0,0 +1,73 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tar_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/homeport/forklift/internal/imagetest"
+	forklift "github.com/homeport/forklift/pkg/tar"
+)
+
+var _ = Describe("Extract", func() {
+	It("should extract files and directories", func() {
+		var dir = GinkgoT().TempDir()
+		Expect(forklift.ExtractLayer(imagetest.Layer("etc/", "etc/app.conf"), dir)).To(Succeed())
+
+		data, err := os.ReadFile(filepath.Join(dir, "etc", "app.conf"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal("etc/app.conf"))
+	})
+
+	It("should fail with a typed error on symbolic links", func() {
+		var buf bytes.Buffer
+		var gzw = gzip.NewWriter(&buf)
+		var tw = tar.NewWriter(gzw)
+		Expect(tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: "usr/lib/", Mode: 0755})).To(Succeed())
+		Expect(tw.WriteHeader(&tar.Header{Typeflag: tar.TypeSymlink, Name: "./usr/lib/libfoo.so", Linkname: "libfoo.so.1"})).To(Succeed())
+		Expect(tw.Close()).To(Succeed())
+		Expect(gzw.Close()).To(Succeed())
+
+		var err = forklift.ExtractCompressed(&buf, GinkgoT().TempDir())
+
+		var entryErr *forklift.UnsupportedEntryError
+		Expect(errors.As(err, &entryErr)).To(BeTrue())
+		Expect(entryErr.Path).To(Equal("usr/lib/libfoo.so"))
+		Expect(entryErr.Type).To(Equal("symbolic link"))
+	})
+
+	It("should fail with a typed error on whiteouts", func() {
+		var err = forklift.ExtractLayer(imagetest.Layer("etc/", "etc/.wh.app.conf"), GinkgoT().TempDir())
+
+		var entryErr *forklift.UnsupportedEntryError
+		Expect(errors.As(err, &entryErr)).To(BeTrue())
+		Expect(entryErr.Path).To(Equal("etc/.wh.app.conf"))
+		Expect(entryErr.Type).To(Equal("whiteout"))
+	})
+})
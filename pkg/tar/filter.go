@@ -0,0 +1,86 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tar
+
+import (
+	"archive/tar"
+	"io"
+	"os"
+	"path"
+	"slices"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// Filter writes all entries of the layer for which keep returns true into a
+// temporary tarball, entry metadata is preserved. It returns the names of
+// the entries that were left out. The caller has to remove the tarball, on
+// error no tarball is left behind.
+func Filter(layer v1.Layer, keep func(header *tar.Header) (bool, error), opts ...Option) (*os.File, []string, error) {
+	var removed []string
+	target, err := tempTarball(newOptions(opts), func(tw *tar.Writer) error {
+		return Walk(layer, func(header *tar.Header, r io.Reader) error {
+			ok, err := keep(header)
+			if err != nil {
+				return err
+			}
+
+			if !ok {
+				removed = append(removed, Normalize(header.Name))
+				return nil
+			}
+
+			if err := tw.WriteHeader(header); err != nil {
+				return err
+			}
+
+			_, err = io.Copy(tw, r)
+			return err
+		})
+	})
+
+	return target, removed, err
+}
+
+// Whiteouts creates a temporary tarball with whiteout entries that delete the
+// given files or directories from the lower layers, see Filter for the
+// removal of the tarball
+func Whiteouts(names []string, opts ...Option) (*os.File, error) {
+	var sorted = slices.Clone(names)
+	slices.Sort(sorted)
+
+	return tempTarball(newOptions(opts), func(tw *tar.Writer) error {
+		for _, name := range sorted {
+			var dir, base = path.Split(Normalize(name))
+			err := tw.WriteHeader(&tar.Header{
+				Typeflag: tar.TypeReg,
+				Name:     dir + WhiteoutPrefix + base,
+				Mode:     0600,
+			})
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,111 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tar
+
+import (
+	"path"
+	"strings"
+)
+
+// Match reports whether the name, or any of its parent directories, matches
+// the shell pattern, where ** matches any number of path elements. Leading
+// slashes and ./ are ignored for both pattern and name.
+func Match(pattern, name string) (bool, error) {
+	var patternElems = split(pattern)
+	var nameElems = split(name)
+
+	for i := len(nameElems); i > 0; i-- {
+		matched, err := matchElems(patternElems, nameElems[:i])
+		if err != nil || matched {
+			return matched, err
+		}
+	}
+
+	return false, nil
+}
+
+// MatchAny reports whether any of the patterns matches the name (see Match)
+func MatchAny(patterns []string, name string) (bool, error) {
+	for _, pattern := range patterns {
+		matched, err := Match(pattern, name)
+		if err != nil || matched {
+			return matched, err
+		}
+	}
+
+	return false, nil
+}
+
+// ValidatePatterns checks that all patterns are well-formed
+func ValidatePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		for _, elem := range split(pattern) {
+			if _, err := path.Match(elem, ""); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func matchElems(pattern, name []string) (bool, error) {
+	switch {
+	case len(pattern) == 0:
+		return len(name) == 0, nil
+
+	case pattern[0] == "**":
+		for i := 0; i <= len(name); i++ {
+			matched, err := matchElems(pattern[1:], name[i:])
+			if err != nil || matched {
+				return matched, err
+			}
+		}
+
+		return false, nil
+
+	case len(name) == 0:
+		return false, nil
+	}
+
+	matched, err := path.Match(pattern[0], name[0])
+	if err != nil || !matched {
+		return false, err
+	}
+
+	return matchElems(pattern[1:], name[1:])
+}
+
+// Normalize returns the name as it is used to compare tarball entries, that
+// is without leading slashes, ./ and trailing slashes
+func Normalize(name string) string {
+	name = path.Clean("/" + name)
+	return strings.TrimPrefix(name, "/")
+}
+
+func split(name string) []string {
+	name = Normalize(name)
+	if name == "" {
+		return nil
+	}
+
+	return strings.Split(name, "/")
+}
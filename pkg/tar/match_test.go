@@ -0,0 +1,68 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tar_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/homeport/forklift/internal/imagetest"
+	"github.com/homeport/forklift/pkg/tar"
+)
+
+var _ = Describe("Match", func() {
+	DescribeTable("glob patterns",
+		func(pattern string, name string, expected bool) {
+			matched, err := tar.Match(pattern, name)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(matched).To(Equal(expected))
+		},
+		Entry("exact name", "etc/passwd", "etc/passwd", true),
+		Entry("leading slash and ./", "/etc/passwd", "./etc/passwd", true),
+		Entry("single element wildcard", "etc/*.conf", "etc/app.conf", true),
+		Entry("single element wildcard does not cross directories", "etc/*.conf", "etc/app/app.conf", false),
+		Entry("content of matching directory", "root/.ssh", "root/.ssh/id_rsa", true),
+		Entry("double star prefix", "**/*.pem", "etc/ssl/certs/ca.pem", true),
+		Entry("double star prefix at top level", "**/*.pem", "ca.pem", true),
+		Entry("double star in the middle", "usr/**/lib", "usr/local/share/lib", true),
+		Entry("double star suffix", "usr/lib/**", "usr/lib/x/y", true),
+		Entry("no match", "usr/lib/**", "usr/bin/sh", false),
+	)
+
+	It("should report malformed patterns", func() {
+		Expect(tar.ValidatePatterns([]string{"etc/[a-"})).ToNot(Succeed())
+	})
+})
+
+var _ = Describe("Files", func() {
+	It("should honor whiteouts of upper layers", func() {
+		files, err := tar.Files(
+			imagetest.Layer("etc/", "etc/passwd", "etc/secret", "opt/", "opt/a", "opt/b"),
+			imagetest.Layer("etc/.wh.secret", "opt/.wh..wh..opq", "opt/c"),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(files).To(HaveLen(4))
+		Expect(files).To(HaveKey("etc/passwd"))
+		Expect(files).To(HaveKey("opt/c"))
+		Expect(files).ToNot(HaveKey("etc/secret"))
+		Expect(files).ToNot(HaveKey("opt/a"))
+	})
+})
@@ -0,0 +1,117 @@
+// Copyright © 2025 The Homeport Team
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tar
+
+import (
+	"archive/tar"
+	"errors"
+	"io"
+	"path"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+const (
+	// WhiteoutPrefix marks a file or directory as deleted in the lower layers
+	WhiteoutPrefix = ".wh."
+
+	// WhiteoutOpaque marks a directory as opaque, hiding all lower layer content
+	WhiteoutOpaque = WhiteoutPrefix + WhiteoutPrefix + ".opq"
+)
+
+// WalkFunc is called for each entry of a layer, the reader provides the
+// content of the entry
+type WalkFunc func(header *tar.Header, r io.Reader) error
+
+// Walk calls the function for each entry of the uncompressed layer
+func Walk(layer v1.Layer, fn WalkFunc) error {
+	rc, err := layer.Uncompressed()
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = rc.Close() }()
+
+	var tr = tar.NewReader(rc)
+	for {
+		header, err := tr.Next()
+		switch {
+		case errors.Is(err, io.EOF):
+			return nil
+
+		case err != nil:
+			return err
+		}
+
+		if err := fn(header, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// Files returns the entries of the file system that results from applying
+// the layers on top of each other, honoring whiteouts, by normalized name
+func Files(layers ...v1.Layer) (map[string]*tar.Header, error) {
+	var result = map[string]*tar.Header{}
+
+	var remove = func(name string, keepDir bool) {
+		for existing := range result {
+			if strings.HasPrefix(existing, name+"/") || (!keepDir && existing == name) {
+				delete(result, existing)
+			}
+		}
+	}
+
+	for _, layer := range layers {
+		var entries []*tar.Header
+		err := Walk(layer, func(header *tar.Header, _ io.Reader) error {
+			entries = append(entries, header)
+			return nil
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		// whiteouts only affect lower layers, so apply them first
+		for _, header := range entries {
+			var name = Normalize(header.Name)
+			var dir, base = path.Split(name)
+			switch {
+			case base == WhiteoutOpaque:
+				remove(strings.TrimSuffix(dir, "/"), true)
+
+			case strings.HasPrefix(base, WhiteoutPrefix):
+				remove(dir+strings.TrimPrefix(base, WhiteoutPrefix), false)
+			}
+		}
+
+		for _, header := range entries {
+			var name = Normalize(header.Name)
+			if !strings.HasPrefix(path.Base(name), WhiteoutPrefix) && name != "" {
+				result[name] = header
+			}
+		}
+	}
+
+	return result, nil
+}